@@ -0,0 +1,202 @@
+package avl
+
+import "iter"
+
+// Monoid describes how to summarize the items in an Augmented.
+// Combine must be associative, and combining Identity with any summary must return that summary.
+// Combine does not need to be commutative -- a always summarizes items that sort before the ones b summarizes.
+//
+// For example, a Monoid that sums ints would be:
+//
+//	Monoid[int, int]{
+//	    Of:      func(v int) int { return v },
+//	    Combine: func(a, b int) int { return a + b },
+//	}
+type Monoid[T, S any] struct {
+	Identity S              // The summary of no items at all.
+	Of       func(T) S      // Summarizes a single item.
+	Combine  func(a, b S) S // Summarizes the items summarized by a followed by the items summarized by b.
+}
+
+// augItem is an item stored in the Tree that backs an Augmented, along with the summary
+// of the subtree rooted at the node that holds it.
+type augItem[T, S any] struct {
+	i T
+	s S
+}
+
+// item returns the item v holds, for use with through.
+func (v augItem[T, S]) item() T { return v.i }
+
+// Augmented is an immutable AVL Tree where every node also holds a summary of the items in its
+// subtree, built using a Monoid.  The summaries are kept up to date on the same copy-on-write path
+// that insert and delete operations use, which lets Aggregate summarize any range of items
+// in O(log n) time instead of visiting each of them.
+type Augmented[T, S any] struct {
+	t    *Tree[augItem[T, S]]
+	m    Monoid[T, S]
+	less LessThan[T]
+}
+
+// NewAugmented allocates a new Augmented that will keep itself ordered according to lt and
+// summarize its items with m.
+func NewAugmented[T, S any](lt LessThan[T], m Monoid[T, S], items ...T) *Augmented[T, S] {
+	t := New[augItem[T, S]](func(a, b augItem[T, S]) bool { return lt(a.i, b.i) })
+	t.aug = func(n *node[augItem[T, S]]) {
+		s := m.Of(n.i.i)
+		if n.c[l] != nil {
+			s = m.Combine(n.c[l].i.s, s)
+		}
+		if n.c[r] != nil {
+			s = m.Combine(s, n.c[r].i.s)
+		}
+		n.i.s = s
+	}
+	res := &Augmented[T, S]{t: t, m: m, less: lt}
+	return res.Insert(items...)
+}
+
+// with wraps t in a new Augmented that has the same ordering and Monoid as a.
+func (a *Augmented[T, S]) with(t *Tree[augItem[T, S]]) *Augmented[T, S] {
+	return &Augmented[T, S]{t: t, m: a.m, less: a.less}
+}
+
+// sum returns the summary of the subtree at n.
+func (a *Augmented[T, S]) sum(n *node[augItem[T, S]]) S {
+	if n == nil {
+		return a.m.Identity
+	}
+	return n.i.s
+}
+
+// Less returns the LessThan function that the Augmented is using.
+func (a *Augmented[T, S]) Less() LessThan[T] {
+	return a.less
+}
+
+// Cmp takes a reference T and makes a valid CompareAgainst using the Augmented's LessThan.
+func (a *Augmented[T, S]) Cmp(reference T) CompareAgainst[T] {
+	return cmpFor(a.less, reference)
+}
+
+// Len returns the number of items in the Augmented.
+func (a *Augmented[T, S]) Len() int { return a.t.Len() }
+
+// Insert returns a new Augmented that has the data from a and any passed-in data.
+func (a *Augmented[T, S]) Insert(items ...T) *Augmented[T, S] {
+	return a.with(a.t.InsertWith(func(f func(augItem[T, S])) {
+		for i := range items {
+			f(augItem[T, S]{i: items[i]})
+		}
+	}))
+}
+
+// Delete returns a new Augmented with the passed-in item removed, along with the removed
+// item and whether an item was removed.
+func (a *Augmented[T, S]) Delete(item T) (into *Augmented[T, S], deleted T, found bool) {
+	t, d, found := a.t.Delete(augItem[T, S]{i: item})
+	return a.with(t), d.i, found
+}
+
+// DeleteRange returns a new Augmented without any of the items that Range would iterate over for
+// the same start and stop Tests, along with the number of items removed.
+func (a *Augmented[T, S]) DeleteRange(start, stop Test[T]) (into *Augmented[T, S], deleted int) {
+	var t *Tree[augItem[T, S]]
+	t, deleted = a.t.DeleteRange(through(start, augItem[T, S].item), through(stop, augItem[T, S].item))
+	return a.with(t), deleted
+}
+
+// Get returns the item in the Augmented that is equal to CompareAgainst and true,
+// or a zero T and false if there is no such item.
+func (a *Augmented[T, S]) Get(cmp CompareAgainst[T]) (item T, found bool) {
+	v, found := a.t.Get(through(cmp, augItem[T, S].item))
+	return v.i, found
+}
+
+// Values returns an iter.Seq that yields every item in ascending order.
+func (a *Augmented[T, S]) Values() iter.Seq[T] {
+	return a.Between(nil, nil)
+}
+
+// Between returns an iter.Seq that yields the items Range would for the same start and stop Tests.
+func (a *Augmented[T, S]) Between(start, stop Test[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range a.t.Between(through(start, augItem[T, S].item), through(stop, augItem[T, S].item)) {
+			if !yield(v.i) {
+				return
+			}
+		}
+	}
+}
+
+// Range will iterate through the Augmented in ascending order,
+// ignoring all items to the left that start returns true for
+// and all items in the right that end returns true for.
+// Iteration will also stop if iterator returns false.
+//
+// Lt  start == inclusive, Lte start == exclusive
+// Gte stop  == exclusive, Gt  stop  == inclusive
+func (a *Augmented[T, S]) Range(start, stop, iterator Test[T]) {
+	a.t.Range(through(start, augItem[T, S].item), through(stop, augItem[T, S].item), func(v augItem[T, S]) bool { return iterator(v.i) })
+}
+
+// Summary returns the summary of every item in the Augmented in O(1) time.
+func (a *Augmented[T, S]) Summary() S {
+	return a.sum(a.t.root)
+}
+
+// Aggregate returns the summary of the items that Range would iterate over when passed
+// the same start and stop Tests.  If either start or stop is nil, then that condition will not apply.
+// Aggregate runs in O(log n) time, no matter how many items are in the range.
+//
+// Lt  start == inclusive, Lte start == exclusive
+// Gte stop  == exclusive, Gt  stop  == inclusive
+func (a *Augmented[T, S]) Aggregate(start, stop Test[T]) S {
+	n := a.t.root
+	// Find the topmost node that is in range.  Everything in range is in its subtree.
+	for n != nil {
+		switch {
+		case start != nil && start(n.i.i):
+			n = n.c[r]
+		case stop != nil && stop(n.i.i):
+			n = n.c[l]
+		default:
+			return a.m.Combine(a.m.Combine(a.after(n.c[l], start), a.m.Of(n.i.i)), a.before(n.c[r], stop))
+		}
+	}
+	return a.m.Identity
+}
+
+// after summarizes the items in the subtree at n that start returns false for.
+func (a *Augmented[T, S]) after(n *node[augItem[T, S]], start Test[T]) S {
+	if start == nil {
+		return a.sum(n)
+	}
+	res := a.m.Identity
+	for n != nil {
+		if start(n.i.i) {
+			n = n.c[r]
+			continue
+		}
+		res = a.m.Combine(a.m.Combine(a.m.Of(n.i.i), a.sum(n.c[r])), res)
+		n = n.c[l]
+	}
+	return res
+}
+
+// before summarizes the items in the subtree at n that stop returns false for.
+func (a *Augmented[T, S]) before(n *node[augItem[T, S]], stop Test[T]) S {
+	if stop == nil {
+		return a.sum(n)
+	}
+	res := a.m.Identity
+	for n != nil {
+		if stop(n.i.i) {
+			n = n.c[l]
+			continue
+		}
+		res = a.m.Combine(res, a.m.Combine(a.sum(n.c[l]), a.m.Of(n.i.i)))
+		n = n.c[r]
+	}
+	return res
+}
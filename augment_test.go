@@ -0,0 +1,104 @@
+package avl
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+var sumMonoid = Monoid[int, int]{
+	Of:      func(v int) int { return v },
+	Combine: func(a, b int) int { return a + b },
+}
+
+// concatMonoid is not commutative, so it catches summaries combined in the wrong order.
+var concatMonoid = Monoid[int, string]{
+	Of:      func(v int) string { return strconv.Itoa(v) + "," },
+	Combine: func(a, b string) string { return a + b },
+}
+
+// checkSummaries makes sure the summary held by every node matches its subtree.
+func checkSummaries[T, S comparable](t *testing.T, a *Augmented[T, S]) {
+	t.Helper()
+	var walk func(n *node[augItem[T, S]]) S
+	walk = func(n *node[augItem[T, S]]) S {
+		if n == nil {
+			return a.m.Identity
+		}
+		s := a.m.Combine(a.m.Combine(walk(n.c[l]), a.m.Of(n.i.i)), walk(n.c[r]))
+		if s != n.i.s {
+			t.Fatalf("Summary at %v is %v, expected %v", n.i.i, n.i.s, s)
+		}
+		return s
+	}
+	walk(a.t.root)
+	a.t.root.balanced(t)
+}
+
+func TestAugmented(t *testing.T) {
+	src := rand.New(rand.NewSource(19))
+	sums := NewAugmented[int, int](il, sumMonoid)
+	strs := NewAugmented[int, string](il, concatMonoid)
+	for i := 0; i < 1000; i++ {
+		v := src.Intn(300)
+		if src.Intn(3) == 0 {
+			sums, _, _ = sums.Delete(v)
+			strs, _, _ = strs.Delete(v)
+		} else {
+			sums = sums.Insert(v)
+			strs = strs.Insert(v)
+		}
+		if i%50 == 0 {
+			checkSummaries(t, sums)
+			checkSummaries(t, strs)
+		}
+	}
+	checkSummaries(t, sums)
+	checkSummaries(t, strs)
+	strs, _ = strs.DeleteRange(Lt(strs.Cmp(100)), Gt(strs.Cmp(150)))
+	checkSummaries(t, strs)
+	makers := []TestMaker[int]{nil, Lt[int], Lte[int], Gt[int], Gte[int]}
+	for i := 0; i < 500; i++ {
+		var start, stop Test[int]
+		if m := makers[src.Intn(3)]; m != nil {
+			start = m(sums.Cmp(src.Intn(320) - 10))
+		}
+		if m := makers[[]int{0, 3, 4}[src.Intn(3)]]; m != nil {
+			stop = m(sums.Cmp(src.Intn(320) - 10))
+		}
+		var sum int
+		var str string
+		sums.Range(start, stop, func(v int) bool {
+			sum += v
+			return true
+		})
+		strs.Range(start, stop, func(v int) bool {
+			str += strconv.Itoa(v) + ","
+			return true
+		})
+		if got := sums.Aggregate(start, stop); got != sum {
+			t.Fatalf("Aggregate sum: expected %d, got %d", sum, got)
+		}
+		if got := strs.Aggregate(start, stop); got != str {
+			t.Fatalf("Aggregate concat: expected %q, got %q", str, got)
+		}
+	}
+	if sums.Summary() != sums.Aggregate(nil, nil) {
+		t.Fatalf("Summary does not match Aggregate over everything")
+	}
+}
+
+func TestAugmentedReplace(t *testing.T) {
+	a := NewAugmented[ovr, int](ol, Monoid[ovr, int]{
+		Of:      func(v ovr) int { return v.mark },
+		Combine: func(a, b int) int { return a + b },
+	})
+	for i := 0; i < 100; i++ {
+		a = a.Insert(ovr{i: i, mark: 1})
+	}
+	b := a.Insert(ovr{i: 50, mark: 101})
+	if a.Summary() != 100 || b.Summary() != 200 {
+		t.Fatalf("Replacing an item did not update summaries: %d %d", a.Summary(), b.Summary())
+	}
+	checkSummaries(t, b)
+}
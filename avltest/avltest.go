@@ -0,0 +1,385 @@
+// Package avltest provides model-based randomized testing for avl.Tree and types built on it.
+//
+// It generates random sequences of Insert, Delete, Fork, iterate and range operations, runs them
+// against a Subject and against a simple sorted slice model, and checks that they agree after every
+// step.  Since avl Trees are persistent, every operation works on one of several live versions and
+// produces a new one, and the versions an operation did not touch are checked to make sure they
+// did not change.  Failing sequences are shrunk to a minimal sequence that still fails before they are reported.
+//
+// Use Run from ordinary tests, and Fuzz from native Go fuzz targets:
+//
+//	func TestMyTree(t *testing.T) {
+//	    avltest.Run(t, cfg, 1)
+//	}
+//
+//	func FuzzMyTree(f *testing.F) {
+//	    f.Fuzz(func(t *testing.T, data []byte) { avltest.Fuzz(t, cfg, data) })
+//	}
+package avltest
+
+import (
+	"encoding/binary"
+	"fmt"
+	"iter"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/VictorLowther/avl"
+)
+
+// Subject is the ordered set being tested.  Implementations must be persistent: Insert, Delete and
+// Fork return a new Subject and leave the one they were called on unchanged.
+type Subject[T any] interface {
+	Insert(item T) Subject[T]
+	Delete(item T) (res Subject[T], found bool)
+	Fork() Subject[T]
+	Len() int
+	// Values returns every item in ascending order.
+	Values() iter.Seq[T]
+	// Range returns the items that are not less than lo and are less than hi, in ascending order.
+	Range(lo, hi T) iter.Seq[T]
+	// Check returns an error if any of the Subject's internal invariants do not hold.
+	Check() error
+}
+
+// treeSubject adapts an *avl.Tree to Subject.
+type treeSubject[T any] struct {
+	t *avl.Tree[T]
+}
+
+// Tree returns a Subject that tests t.
+func Tree[T any](t *avl.Tree[T]) Subject[T] {
+	return treeSubject[T]{t: t}
+}
+
+func (s treeSubject[T]) Insert(item T) Subject[T] { return treeSubject[T]{t: s.t.Insert(item)} }
+
+func (s treeSubject[T]) Delete(item T) (Subject[T], bool) {
+	t, _, found := s.t.Delete(item)
+	return treeSubject[T]{t: t}, found
+}
+
+func (s treeSubject[T]) Fork() Subject[T]    { return treeSubject[T]{t: s.t.Fork()} }
+func (s treeSubject[T]) Len() int            { return s.t.Len() }
+func (s treeSubject[T]) Values() iter.Seq[T] { return s.t.Values() }
+func (s treeSubject[T]) Check() error        { return s.t.Check() }
+
+func (s treeSubject[T]) Range(lo, hi T) iter.Seq[T] {
+	return s.t.Between(avl.Lt(s.t.Cmp(lo)), avl.Gte(s.t.Cmp(hi)))
+}
+
+// Config describes how to test a Subject.
+type Config[T any] struct {
+	Less avl.LessThan[T]      // The ordering the Subject keeps its items in.
+	Item func(r *rand.Rand) T // Generates a random item.
+	New  func() Subject[T]    // Creates a new, empty Subject.
+	Ops  int                  // Number of operations in each generated sequence.  Defaults to 200.
+	Keep int                  // Number of versions to keep alive at a time.  Defaults to 4.
+	Eq   func(a, b T) bool    // Compares items for exact equality.  Defaults to reflect.DeepEqual.
+}
+
+func (cfg *Config[T]) defaults() {
+	if cfg.Ops <= 0 {
+		cfg.Ops = 200
+	}
+	if cfg.Keep <= 0 {
+		cfg.Keep = 4
+	}
+	if cfg.Eq == nil {
+		cfg.Eq = func(a, b T) bool { return reflect.DeepEqual(a, b) }
+	}
+}
+
+// OpKind is the kind of operation an Op performs.
+type OpKind int
+
+const (
+	Insert  OpKind = iota // Insert Item into the version.
+	Delete                // Delete Item from the version.
+	Fork                  // Fork the version.
+	Iterate               // Compare every item in the version with the model.
+	Range                 // Compare the items between Item and Hi in the version with the model.
+)
+
+func (k OpKind) String() string {
+	switch k {
+	case Insert:
+		return "Insert"
+	case Delete:
+		return "Delete"
+	case Fork:
+		return "Fork"
+	case Iterate:
+		return "Iterate"
+	case Range:
+		return "Range"
+	default:
+		return fmt.Sprintf("OpKind(%d)", int(k))
+	}
+}
+
+// Op is a single step in a test sequence.  Version picks which of the live versions the Op works on by
+// counting back from the most recently made one, modulo the number of versions alive at the time, so
+// any subsequence of a sequence is still valid.  Insert, Delete and Fork add the version they make to
+// the live versions, dropping the oldest one if there are too many.
+type Op[T any] struct {
+	Kind     OpKind
+	Version  int
+	Item, Hi T
+}
+
+func (o Op[T]) String() string {
+	switch o.Kind {
+	case Insert, Delete:
+		return fmt.Sprintf("%v(v%d, %v)", o.Kind, o.Version, o.Item)
+	case Range:
+		return fmt.Sprintf("%v(v%d, %v, %v)", o.Kind, o.Version, o.Item, o.Hi)
+	default:
+		return fmt.Sprintf("%v(v%d)", o.Kind, o.Version)
+	}
+}
+
+// Failure is returned by Execute when a Subject disagrees with the model or fails its Check.
+type Failure[T any] struct {
+	Step int    // Index of the failing Op.
+	Op   Op[T]  // The failing Op.
+	Msg  string // What went wrong.
+}
+
+func (f *Failure[T]) Error() string {
+	return fmt.Sprintf("step %d: %v: %s", f.Step, f.Op, f.Msg)
+}
+
+// Generate makes a random sequence of cfg.Ops operations using r.
+func Generate[T any](cfg Config[T], r *rand.Rand) []Op[T] {
+	cfg.defaults()
+	res := make([]Op[T], cfg.Ops)
+	for i := range res {
+		op := Op[T]{Version: r.Intn(cfg.Keep), Item: cfg.Item(r)}
+		// Favor inserts and deletes so the versions grow big enough to be interesting.
+		switch n := r.Intn(10); {
+		case n < 4:
+			op.Kind = Insert
+		case n < 7:
+			op.Kind = Delete
+		case n < 8:
+			op.Kind = Fork
+		case n < 9:
+			op.Kind = Iterate
+		default:
+			op.Kind = Range
+			op.Hi = cfg.Item(r)
+			if cfg.Less(op.Hi, op.Item) {
+				op.Item, op.Hi = op.Hi, op.Item
+			}
+		}
+		res[i] = op
+	}
+	return res
+}
+
+// version is a Subject along with the sorted slice model of what it should hold.
+type version[T any] struct {
+	s     Subject[T]
+	model []T
+}
+
+// search returns where item is or would be in model.
+func search[T any](less avl.LessThan[T], model []T, item T) (int, bool) {
+	i := sort.Search(len(model), func(i int) bool { return !less(model[i], item) })
+	return i, i < len(model) && !less(item, model[i])
+}
+
+// compare returns an error message if got does not hold exactly the items in expect.
+func compare[T any](cfg Config[T], what string, got iter.Seq[T], expect []T) string {
+	i := 0
+	for v := range got {
+		if i >= len(expect) {
+			return fmt.Sprintf("%s returned extra item %v", what, v)
+		}
+		if !cfg.Eq(v, expect[i]) {
+			return fmt.Sprintf("%s returned %v at position %d, expected %v", what, v, i, expect[i])
+		}
+		i++
+	}
+	if i < len(expect) {
+		return fmt.Sprintf("%s returned %d items, expected %d", what, i, len(expect))
+	}
+	return ""
+}
+
+// verify checks that v agrees with its model.
+func verify[T any](cfg Config[T], v version[T]) string {
+	if err := v.s.Check(); err != nil {
+		return fmt.Sprintf("Check failed: %v", err)
+	}
+	if v.s.Len() != len(v.model) {
+		return fmt.Sprintf("Len is %d, expected %d", v.s.Len(), len(v.model))
+	}
+	return compare(cfg, "Values", v.s.Values(), v.model)
+}
+
+// Execute runs ops against a new Subject and the model, returning a *Failure for the first step at
+// which they disagree, or nil if they always agree.  Panics in the Subject are reported as Failures.
+func Execute[T any](cfg Config[T], ops []Op[T]) (err error) {
+	cfg.defaults()
+	versions := []version[T]{{s: cfg.New()}}
+	step := 0
+	defer func() {
+		if p := recover(); p != nil {
+			err = &Failure[T]{Step: step, Op: ops[step], Msg: fmt.Sprintf("panic: %v", p)}
+		}
+	}()
+	for ; step < len(ops); step++ {
+		op := ops[step]
+		fail := func(format string, args ...any) error {
+			return &Failure[T]{Step: step, Op: op, Msg: fmt.Sprintf(format, args...)}
+		}
+		cur := versions[len(versions)-1-op.Version%len(versions)]
+		var next version[T]
+		switch op.Kind {
+		case Insert:
+			next.s = cur.s.Insert(op.Item)
+			i, found := search(cfg.Less, cur.model, op.Item)
+			next.model = append(append(append([]T{}, cur.model[:i]...), op.Item), cur.model[i:]...)
+			if found {
+				next.model = append(next.model[:i+1], next.model[i+2:]...)
+			}
+		case Delete:
+			var found bool
+			next.s, found = cur.s.Delete(op.Item)
+			i, expect := search(cfg.Less, cur.model, op.Item)
+			if found != expect {
+				return fail("Delete found %v, expected %v", found, expect)
+			}
+			next.model = append([]T{}, cur.model...)
+			if found {
+				next.model = append(next.model[:i], next.model[i+1:]...)
+			}
+		case Fork:
+			next = version[T]{s: cur.s.Fork(), model: cur.model}
+		case Iterate:
+			// Every live version must still match its model, no matter what was done to the others.
+			for i, v := range versions {
+				if msg := verify(cfg, v); msg != "" {
+					return fail("version %d: %s", i, msg)
+				}
+			}
+			continue
+		case Range:
+			lo, _ := search(cfg.Less, cur.model, op.Item)
+			hi, _ := search(cfg.Less, cur.model, op.Hi)
+			if msg := compare(cfg, "Range", cur.s.Range(op.Item, op.Hi), cur.model[lo:hi]); msg != "" {
+				return fail("%s", msg)
+			}
+			continue
+		default:
+			return fail("unknown operation")
+		}
+		if msg := verify(cfg, next); msg != "" {
+			return fail("new version: %s", msg)
+		}
+		// Check the version we started from, to make sure the operation did not change it.
+		if msg := verify(cfg, cur); msg != "" {
+			return fail("original version changed: %s", msg)
+		}
+		versions = append(versions, next)
+		if len(versions) > cfg.Keep {
+			versions = versions[1:]
+		}
+	}
+	return nil
+}
+
+// Shrink returns the shortest subsequence of ops it can find that still makes Execute fail.
+// ops must make Execute fail to begin with.
+func Shrink[T any](cfg Config[T], ops []Op[T]) []Op[T] {
+	fails := func(ops []Op[T]) bool { return Execute(cfg, ops) != nil }
+	// Nothing after the failing step matters.
+	var f *Failure[T]
+	if err := Execute(cfg, ops); err != nil {
+		if f, _ = err.(*Failure[T]); f != nil {
+			ops = ops[:f.Step+1]
+		}
+	}
+	for shrunk := true; shrunk; {
+		shrunk = false
+		// Try removing chunks of ops, starting big and getting smaller.
+		for size := len(ops) / 2; size > 0; size /= 2 {
+			for start := 0; start+size <= len(ops); {
+				candidate := append(append([]Op[T]{}, ops[:start]...), ops[start+size:]...)
+				if fails(candidate) {
+					ops, shrunk = candidate, true
+				} else {
+					start += size
+				}
+			}
+		}
+		// Working on the newest version makes the sequence easier to follow, and
+		// can make more ops removable on the next pass.
+		for i := range ops {
+			if ops[i].Version == 0 {
+				continue
+			}
+			candidate := append([]Op[T]{}, ops...)
+			candidate[i].Version = 0
+			if fails(candidate) {
+				ops, shrunk = candidate, true
+			}
+		}
+	}
+	return ops
+}
+
+// report shrinks a failing sequence and fails t with it.
+func report[T any](t testing.TB, cfg Config[T], ops []Op[T], err error) {
+	t.Helper()
+	shrunk := Shrink(cfg, ops)
+	var buf strings.Builder
+	for i, op := range shrunk {
+		fmt.Fprintf(&buf, "\n\t%d: %v", i, op)
+	}
+	t.Fatalf("%v\nshrunk from %d to %d operations, failing with %v:%s", err, len(ops), len(shrunk), Execute(cfg, shrunk), buf.String())
+}
+
+// Run generates a random sequence of operations from seed, runs it, and fails t with a shrunk
+// sequence if the Subject ever disagrees with the model.
+func Run[T any](t testing.TB, cfg Config[T], seed int64) {
+	t.Helper()
+	ops := Generate(cfg, rand.New(rand.NewSource(seed)))
+	if err := Execute(cfg, ops); err != nil {
+		report(t, cfg, ops, err)
+	}
+}
+
+// byteSource is a rand.Source that returns numbers made from a fuzzer's input, and then zeros
+// once the input runs out.  That lets the fuzzer steer the generated operations.
+type byteSource struct {
+	data []byte
+}
+
+func (b *byteSource) Uint64() uint64 {
+	var buf [8]byte
+	n := copy(buf[:], b.data)
+	b.data = b.data[n:]
+	return binary.LittleEndian.Uint64(buf[:])
+}
+
+func (b *byteSource) Int63() int64 { return int64(b.Uint64() >> 1) }
+func (b *byteSource) Seed(int64)   {}
+
+// Fuzz generates a sequence of operations from data, runs it, and fails t with a shrunk sequence if
+// the Subject ever disagrees with the model.  It is meant to be called from a native Go fuzz target.
+// Every 16 bytes of data makes one operation, up to cfg.Ops operations.
+func Fuzz[T any](t *testing.T, cfg Config[T], data []byte) {
+	t.Helper()
+	cfg.defaults()
+	cfg.Ops = min(cfg.Ops, len(data)/16+1)
+	ops := Generate(cfg, rand.New(&byteSource{data: data}))
+	if err := Execute(cfg, ops); err != nil {
+		report(t, cfg, ops, err)
+	}
+}
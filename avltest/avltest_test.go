@@ -0,0 +1,85 @@
+package avltest
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/VictorLowther/avl"
+)
+
+type item struct{ k, v int }
+
+func itemLess(a, b item) bool { return a.k < b.k }
+
+func itemConfig(newSubject func() Subject[item]) Config[item] {
+	return Config[item]{
+		Less: itemLess,
+		Item: func(r *rand.Rand) item { return item{k: r.Intn(100), v: r.Intn(3)} },
+		New:  newSubject,
+	}
+}
+
+func TestTree(t *testing.T) {
+	cfg := itemConfig(func() Subject[item] { return Tree(avl.New[item](itemLess)) })
+	for seed := int64(0); seed < 20; seed++ {
+		Run(t, cfg, seed)
+	}
+}
+
+func FuzzTree(f *testing.F) {
+	cfg := itemConfig(func() Subject[item] { return Tree(avl.New[item](itemLess)) })
+	f.Add([]byte("avltest"))
+	f.Add(make([]byte, 256))
+	f.Fuzz(func(t *testing.T, data []byte) { Fuzz(t, cfg, data) })
+}
+
+// leaky is a Subject that never really deletes key 13.
+type leaky struct{ Subject[item] }
+
+func (s leaky) Insert(i item) Subject[item] { return leaky{s.Subject.Insert(i)} }
+func (s leaky) Fork() Subject[item]         { return leaky{s.Subject.Fork()} }
+
+func (s leaky) Delete(i item) (Subject[item], bool) {
+	res, found := s.Subject.Delete(i)
+	if i.k == 13 {
+		return s, found
+	}
+	return leaky{res}, found
+}
+
+// fakeTB captures the failure Run reports.
+type fakeTB struct {
+	testing.TB
+	msg string
+}
+
+func (f *fakeTB) Helper()                           {}
+func (f *fakeTB) Fatalf(format string, args ...any) { f.msg = fmt.Sprintf(format, args...) }
+
+func TestShrink(t *testing.T) {
+	cfg := itemConfig(func() Subject[item] { return leaky{Tree(avl.New[item](itemLess))} })
+	cfg.Item = func(r *rand.Rand) item { return item{k: r.Intn(20)} }
+	var ops []Op[item]
+	var err error
+	for seed := int64(0); err == nil; seed++ {
+		ops = Generate(cfg, rand.New(rand.NewSource(seed)))
+		err = Execute(cfg, ops)
+	}
+	shrunk := Shrink(cfg, ops)
+	if Execute(cfg, shrunk) == nil {
+		t.Fatalf("Shrink returned a sequence that passes")
+	}
+	// Inserting 13 and then deleting it is all it takes.
+	if len(shrunk) != 2 || shrunk[0].Kind != Insert || shrunk[1].Kind != Delete || shrunk[1].Item.k != 13 {
+		t.Fatalf("Shrink: expected Insert and Delete of 13, got %v", shrunk)
+	}
+	tb := &fakeTB{TB: t}
+	for seed := int64(0); tb.msg == ""; seed++ {
+		Run(tb, cfg, seed)
+	}
+	if !strings.Contains(tb.msg, "Delete(v0, {13 0})") {
+		t.Fatalf("Run did not report the shrunk sequence: %s", tb.msg)
+	}
+}
@@ -1,6 +1,11 @@
 package avl
 
-import "sync"
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
 
 const (
 	leftHeavy  = -2
@@ -46,6 +51,11 @@ type Tree[T any] struct {
 	less  LessThan[T] // Ordering function used to sort nodes in the Tree.
 	gen   uint64      // Generation count of the tree.  Every insert or delete call increments gen.
 	count int         // Nodes present in the Tree.
+	// Recalculates augmented data for a node from its item and its children.  Only set for Trees that
+	// back an Augmented, which store the augmented data alongside the item in each node.
+	aug func(*node[T])
+	// Called with the work done by each operation that modifies the Tree.  Set by WithCosts.
+	cost func(OpCost)
 }
 
 // getNs fetches a nodeStack from the pool of spare nodestacks.  We cache them in a pool
@@ -53,11 +63,17 @@ type Tree[T any] struct {
 func (t *Tree[T]) getNs() *nodeStack[T] {
 	res := t.nsp.Get().(*nodeStack[T])
 	res.gen = t.gen
+	res.aug = t.aug
+	res.counting = t.cost != nil
+	res.costs = OpCost{}
 	return res
 }
 
 // putNS returns a nodeStack to the pool of spare nodeStacks.
 func (t *Tree[T]) putNs(n *nodeStack[T]) {
+	if t.cost != nil {
+		t.cost(n.costs)
+	}
 	n.s = n.s[:cap(n.s)]
 	for i := range n.s {
 		n.s[i] = nil
@@ -79,6 +95,10 @@ func (t *Tree[T]) insertOne(ins *nodeStack[T], item T) {
 	switch direction {
 	case Equal:
 		n.i = item
+		if ins.aug != nil {
+			// Sizes do not change, but augmented data might.
+			ins.resize(len(ins.s) - 1)
+		}
 		t.root = ins.at(0)
 		return
 	case Less:
@@ -90,6 +110,9 @@ func (t *Tree[T]) insertOne(ins *nodeStack[T], item T) {
 	n.c[addDir] = ins.newNode(item)
 	if n.c[flip(addDir)] == nil {
 		ins.rebalance()
+	} else {
+		// The height of the tree did not change, but every node on the path has one more descendant.
+		ins.resize(len(ins.s) - 1)
 	}
 	t.root = ins.at(0)
 }
@@ -126,6 +149,82 @@ func CreateWith[T any](lt LessThan[T], fill Fill[T]) *Tree[T] {
 	return res
 }
 
+// ErrUnsorted is returned when items that are supposed to be sorted are not.
+var ErrUnsorted = errors.New("items are not sorted")
+
+// build makes a perfectly balanced subtree out of items, which must already be in order with no duplicates.
+func (ns *nodeStack[T]) build(items []T) *node[T] {
+	if len(items) == 0 {
+		return nil
+	}
+	mid := len(items) / 2
+	n := ns.newNode(items[mid])
+	n.c[l], n.c[r] = ns.build(items[:mid]), ns.build(items[mid+1:])
+	ns.setHeight(n)
+	return n
+}
+
+// FromSorted creates a new Tree from items, which must already be sorted according to lt.
+// If there are runs of equal items, only the last one in each run is kept, the same as New would do.
+// The new Tree is perfectly balanced and is built in O(n) time instead of the O(n log n) time
+// that inserting items one at a time takes.  If items is not sorted, FromSorted returns a nil
+// Tree and an error wrapping ErrUnsorted.
+func FromSorted[T any](lt LessThan[T], items []T) (*Tree[T], error) {
+	res := New[T](lt)
+	if err := res.fromSorted(items, false); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// fromSorted checks that items are sorted and makes them the contents of t.  If strict is set,
+// runs of equal items are treated as being out of order, otherwise only the last item in each run is kept.
+func (t *Tree[T]) fromSorted(items []T, strict bool) error {
+	compares, dups := 0, false
+	for i := 1; i < len(items); i++ {
+		compares++
+		if t.less(items[i-1], items[i]) {
+			continue
+		}
+		if strict {
+			return fmt.Errorf("%w: item %d does not sort after item %d", ErrUnsorted, i, i-1)
+		}
+		compares++
+		if t.less(items[i], items[i-1]) {
+			return fmt.Errorf("%w: item %d sorts before item %d", ErrUnsorted, i, i-1)
+		}
+		dups = true
+	}
+	if dups {
+		deduped := make([]T, 0, len(items))
+		for i := range items {
+			if i+1 < len(items) {
+				compares++
+				if !t.less(items[i], items[i+1]) {
+					continue
+				}
+			}
+			deduped = append(deduped, items[i])
+		}
+		items = deduped
+	}
+	ins := t.getNs()
+	defer t.putNs(ins)
+	ins.costs.Compares = compares
+	t.setRoot(ins.build(items))
+	return nil
+}
+
+// CreateSorted is FromSorted for callers that would rather use a Fill.  The items fill passes in
+// are buffered, and then built into a new Tree in O(n) time.
+func CreateSorted[T any](lt LessThan[T], fill Fill[T]) (*Tree[T], error) {
+	var items []T
+	fill(func(v T) {
+		items = append(items, v)
+	})
+	return FromSorted(lt, items)
+}
+
 // Bud creates a new Tree with the passed-in items
 func (t *Tree[T]) Bud(lt LessThan[T], items ...T) *Tree[T] {
 	res := &Tree[T]{less: lt, nsp: t.nsp}
@@ -147,7 +246,22 @@ func (t *Tree[T]) Less() LessThan[T] {
 // Cmp takes a reference T and makes a valid CompareAgainst
 // using the Tree's current LessThan comparator.
 func (t *Tree[T]) Cmp(reference T) CompareAgainst[T] {
-	less := t.less
+	return cmpFor(t.less, reference)
+}
+
+// through converts fn, a Test or CompareAgainst on the items in a type built on top of Tree,
+// into one on the entries in the Tree backing it.  get extracts the item from an entry.
+// A nil fn stays nil, so that it still means there is no bound.
+func through[E, T, R any](fn func(T) R, get func(E) T) func(E) R {
+	if fn == nil {
+		return nil
+	}
+	return func(e E) R { return fn(get(e)) }
+}
+
+// cmpFor makes a CompareAgainst that compares items against reference using less.
+// Cmp for Tree and all of the types built on top of it use it.
+func cmpFor[T any](less LessThan[T], reference T) CompareAgainst[T] {
 	return func(treeVal T) int {
 		if less(treeVal, reference) {
 			return Less
@@ -160,7 +274,7 @@ func (t *Tree[T]) Cmp(reference T) CompareAgainst[T] {
 }
 
 func copyNodes[T any](n *node[T], reverse bool) *node[T] {
-	res := &node[T]{genH: n.h(), i: n.i}
+	res := &node[T]{genH: n.h(), sz: n.sz, i: n.i}
 	for i := range n.c {
 		if n.c[i] != nil {
 			res.c[i] = copyNodes(n.c[i], reverse)
@@ -175,7 +289,7 @@ func copyNodes[T any](n *node[T], reverse bool) *node[T] {
 // Fork makes a new copy of the Tree that has the same ordering function and data.
 // It will share nodes with the original Tree.
 func (t *Tree[T]) Fork() *Tree[T] {
-	res := &Tree[T]{less: t.less, root: t.root, count: t.count, nsp: t.nsp, gen: t.gen + 1}
+	res := &Tree[T]{less: t.less, root: t.root, count: t.count, nsp: t.nsp, gen: t.gen + 1, aug: t.aug, cost: t.cost}
 	if res.gen < maxGen {
 		return res
 	}
@@ -190,6 +304,33 @@ func (t *Tree[T]) Fork() *Tree[T] {
 
 }
 
+// derive makes a new empty Tree with the same ordering function as t whose gen is newer than
+// t and all of others.  Trees that are assembled from nodes borrowed from several other Trees
+// must be newer than all of them, or a later mutation could modify a borrowed node in place.
+func (t *Tree[T]) derive(others ...*Tree[T]) *Tree[T] {
+	gen := t.gen
+	for _, o := range others {
+		if o.gen > gen {
+			gen = o.gen
+		}
+	}
+	return &Tree[T]{less: t.less, nsp: t.nsp, gen: gen + 1, aug: t.aug, cost: t.cost}
+}
+
+// setRoot makes root the root node of t and recalculates the number of items t holds.
+// If t was created by derive with a gen that is too large, setRoot handles resetting it the same way Fork does.
+func (t *Tree[T]) setRoot(root *node[T]) {
+	t.root = root
+	t.count = root.size()
+	if t.gen < maxGen {
+		return
+	}
+	t.gen = 0
+	if t.root != nil {
+		t.root = copyNodes(t.root, false)
+	}
+}
+
 // Reverse returns a reversed copy of Tree.  It will not share any resources with Tree.
 func (t *Tree[T]) Reverse() *Tree[T] {
 	ll := t.less
@@ -225,14 +366,19 @@ func (t *Tree[T]) SortBy(l LessThan[T]) *Tree[T] {
 	}
 }
 
-// SortedClone makes a new Tree using SortBy, then inserts all the data from t into it.
+// SortedClone makes a new Tree using SortBy, then fills it with all the data from t.
+// The data is sorted into its new order and then built into a balanced Tree in one pass,
+// which is faster than inserting each item.
 func (t *Tree[T]) SortedClone(l LessThan[T]) *Tree[T] {
 	res := t.SortBy(l)
-	ins := res.getNs()
-	defer res.putNs(ins)
+	items := make([]T, 0, t.count)
 	for iter := t.All(); iter.Next(); {
-		res.insertOne(ins, iter.Item())
+		items = append(items, iter.Item())
 	}
+	sort.Slice(items, func(i, j int) bool { return res.less(items[i], items[j]) })
+	ins := res.getNs()
+	defer res.putNs(ins)
+	res.setRoot(ins.build(items))
 	return res
 }
 
@@ -307,6 +453,87 @@ func (t *Tree[T]) Max() (item T, found bool) {
 	return
 }
 
+// Select returns the item at position k in the sorted order of the Tree and true,
+// or a zero T and false if k is out of range.  Position 0 is the smallest item.
+// Select runs in O(log n) time.
+func (t *Tree[T]) Select(k int) (item T, found bool) {
+	if k < 0 || k >= t.count {
+		return
+	}
+	n := t.root
+	for n != nil {
+		ls := n.c[l].size()
+		switch {
+		case k < ls:
+			n = n.c[l]
+		case k > ls:
+			k -= ls + 1
+			n = n.c[r]
+		default:
+			return n.i, true
+		}
+	}
+	return
+}
+
+// prefixLen returns the number of items at the start of the Tree that test returns true for.
+// test must be true for some (possibly empty) run of the smallest items in the Tree, and
+// false for everything after that, which is how the Lt and Lte TestMakers behave.
+func (t *Tree[T]) prefixLen(test Test[T]) (res int) {
+	for n := t.root; n != nil; {
+		if test(n.i) {
+			res += n.c[l].size() + 1
+			n = n.c[r]
+		} else {
+			n = n.c[l]
+		}
+	}
+	return
+}
+
+// suffixLen returns the number of items at the end of the Tree that test returns true for.
+// It is the mirror image of prefixLen, and works with the Gt and Gte TestMakers.
+func (t *Tree[T]) suffixLen(test Test[T]) (res int) {
+	for n := t.root; n != nil; {
+		if test(n.i) {
+			res += n.c[r].size() + 1
+			n = n.c[l]
+		} else {
+			n = n.c[r]
+		}
+	}
+	return
+}
+
+// Rank returns the number of items in the Tree that are less than CompareAgainst.
+// If there is an item equal to CompareAgainst, Rank is also its position in the sorted order of the Tree,
+// suitable for passing to Select.  Rank runs in O(log n) time.
+func (t *Tree[T]) Rank(cmp CompareAgainst[T]) int {
+	return t.prefixLen(Lt(cmp))
+}
+
+// CountRange returns the number of items that Range would iterate over
+// when passed the same start and stop Tests, without visiting any of them.
+// If either start or stop is nil, then that condition will not apply.
+// CountRange runs in O(log n) time.
+//
+// Lt  start == inclusive, Lte start == exclusive
+// Gte stop  == exclusive, Gt  stop  == inclusive
+func (t *Tree[T]) CountRange(start, stop Test[T]) int {
+	res := t.count
+	if start != nil {
+		res -= t.prefixLen(start)
+	}
+	if stop != nil {
+		res -= t.suffixLen(stop)
+	}
+	if res < 0 {
+		// start and stop overlap, so there is nothing between them.
+		res = 0
+	}
+	return res
+}
+
 // InsertWith returns a new Tree that has the data from t and any data returned by fill.
 // t and the new Tree will share nodes where possible.
 func (t *Tree[T]) InsertWith(fill Fill[T]) *Tree[T] {
@@ -438,3 +665,43 @@ func (t *Tree[T]) DeleteItems(items ...T) (into *Tree[T], deleted int) {
 	}
 	return
 }
+
+// DeleteRange returns a new Tree that lacks every item that Range would iterate over when passed
+// the same start and stop Tests, along with the number of items that were removed.
+// If either start or stop is nil, then that condition will not apply.
+// The original tree is left unchanged, and the returned Tree will share nodes with it.
+//
+// Instead of deleting items one at a time, DeleteRange cuts the Tree at start and stop and
+// joins the outer parts back together, so it runs in O(log n) time no matter how many items
+// it removes.
+//
+// Lt  start == inclusive, Lte start == exclusive
+// Gte stop  == exclusive, Gt  stop  == inclusive
+func (t *Tree[T]) DeleteRange(start, stop Test[T]) (into *Tree[T], deleted int) {
+	into = t.derive()
+	ins := into.getNs()
+	defer into.putNs(ins)
+	var left, right *node[T]
+	right = t.root
+	if start != nil {
+		left, _, right = ins.split(right, func(v T) int {
+			if start(v) {
+				return Less
+			}
+			return Greater
+		})
+	}
+	if stop != nil {
+		_, _, right = ins.split(right, func(v T) int {
+			if stop(v) {
+				return Greater
+			}
+			return Less
+		})
+	} else {
+		right = nil
+	}
+	into.setRoot(ins.concat(left, right))
+	deleted = t.count - into.count
+	return
+}
@@ -2,6 +2,7 @@ package avl
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
@@ -12,13 +13,6 @@ import (
 	"time"
 )
 
-func (n *node[T]) height() uint64 {
-	if n == nil {
-		return 0
-	}
-	return n.h()
-}
-
 // balanced checks a Tree to ensure it is AVL compliant.
 // Only for use when running tests.
 func (n *node[T]) balanced(t *testing.T) {
@@ -41,6 +35,9 @@ func (n *node[T]) balanced(t *testing.T) {
 	if !(n.h()-lh == 1 || n.h()-rh == 1) {
 		panic("Height not max(lh,rh)+1")
 	}
+	if n.sz != n.c[l].size()+n.c[r].size()+1 {
+		panic("Subtree size incorrect")
+	}
 	b := n.balance()
 	rb := int(rh) - int(lh)
 	if b != rb {
@@ -890,3 +887,254 @@ func TestFetch(t *testing.T) {
 		}
 	}
 }
+
+func TestSelectRank(t *testing.T) {
+	src := rand.New(rand.NewSource(7))
+	n := 1000
+	tree := New[int](il)
+	for _, v := range src.Perm(n) {
+		tree = tree.Insert(v * 2)
+	}
+	tree.root.balanced(t)
+	for i := 0; i < n; i++ {
+		v, ok := tree.Select(i)
+		if !ok || v != i*2 {
+			t.Fatalf("Select(%d): expected %d, got %d %v", i, i*2, v, ok)
+		}
+		if rank := tree.Rank(tree.Cmp(i * 2)); rank != i {
+			t.Fatalf("Rank(%d): expected %d, got %d", i*2, i, rank)
+		}
+		if rank := tree.Rank(tree.Cmp(i*2 + 1)); rank != i+1 {
+			t.Fatalf("Rank(%d): expected %d, got %d", i*2+1, i+1, rank)
+		}
+	}
+	if _, ok := tree.Select(n); ok {
+		t.Fatalf("Select past the end of the Tree found something")
+	}
+	if _, ok := tree.Select(-1); ok {
+		t.Fatalf("Select before the start of the Tree found something")
+	}
+	for i := 0; i < n; i += 3 {
+		tree, _, _ = tree.Delete(i * 2)
+	}
+	tree.root.balanced(t)
+	if tree.root.size() != tree.Len() {
+		t.Fatalf("Root size %d does not match Len %d", tree.root.size(), tree.Len())
+	}
+	for i := 0; i < tree.Len(); i++ {
+		v, _ := tree.Select(i)
+		if rank := tree.Rank(tree.Cmp(v)); rank != i {
+			t.Fatalf("Rank(%d): expected %d, got %d", v, i, rank)
+		}
+	}
+}
+
+func TestCountRange(t *testing.T) {
+	tree := New[int](il, 1, 3, 5, 7, 9, 11)
+	for _, tc := range []struct {
+		start, stop Test[int]
+		expect      int
+	}{
+		{nil, nil, 6},
+		{Lt(tree.Cmp(3)), nil, 5},
+		{Lte(tree.Cmp(3)), nil, 4},
+		{nil, Gt(tree.Cmp(7)), 4},
+		{nil, Gte(tree.Cmp(7)), 3},
+		{Lt(tree.Cmp(3)), Gt(tree.Cmp(9)), 4},
+		{Lte(tree.Cmp(4)), Gte(tree.Cmp(6)), 1},
+		{Lt(tree.Cmp(9)), Gt(tree.Cmp(3)), 0},
+	} {
+		var expect int
+		tree.Range(tc.start, tc.stop, func(int) bool {
+			expect++
+			return true
+		})
+		if expect != tc.expect {
+			t.Fatalf("Range visited %d items, test case expects %d", expect, tc.expect)
+		}
+		if got := tree.CountRange(tc.start, tc.stop); got != tc.expect {
+			t.Fatalf("CountRange: expected %d, got %d", tc.expect, got)
+		}
+	}
+}
+
+func TestFromSorted(t *testing.T) {
+	for n := 0; n < 300; n++ {
+		items := make([]int, n)
+		for i := range items {
+			items[i] = i
+		}
+		tree, err := FromSorted[int](il, items)
+		if err != nil {
+			t.Fatalf("FromSorted(%d): %v", n, err)
+		}
+		tree.root.balanced(t)
+		if got := treeItems(tree); tree.Len() != n || len(got) != n || (n > 0 && !reflect.DeepEqual(got, items)) {
+			t.Fatalf("FromSorted(%d) built the wrong tree", n)
+		}
+		if n > 0 && tree.root.h() > uint64(math.Ceil(math.Log2(float64(n+1)))) {
+			t.Fatalf("FromSorted(%d) is not perfectly balanced: height %d", n, tree.root.h())
+		}
+	}
+	if tree, err := FromSorted[int](il, []int{1, 2, 4, 3}); tree != nil || !errors.Is(err, ErrUnsorted) {
+		t.Fatalf("FromSorted accepted unsorted input: %v", err)
+	}
+	tree, err := FromSorted[ovr](ol, []ovr{{1, 1}, {2, 1}, {2, 2}, {2, 3}, {3, 1}})
+	if err != nil {
+		t.Fatalf("FromSorted with duplicates: %v", err)
+	}
+	expect := []ovr{{1, 1}, {2, 3}, {3, 1}}
+	if got := treeItems(tree); !reflect.DeepEqual(expect, got) || tree.Len() != 3 {
+		t.Fatalf("FromSorted with duplicates: expected %v, got %v", expect, got)
+	}
+	tree2, err := CreateSorted[int](il, func(f func(int)) {
+		for i := 0; i < 100; i++ {
+			f(i)
+		}
+	})
+	if err != nil || tree2.Len() != 100 {
+		t.Fatalf("CreateSorted: %v", err)
+	}
+	tree2.root.balanced(t)
+	if _, err = CreateSorted[int](il, func(f func(int)) { f(2); f(1) }); !errors.Is(err, ErrUnsorted) {
+		t.Fatalf("CreateSorted accepted unsorted input: %v", err)
+	}
+}
+
+func TestSortedClone(t *testing.T) {
+	tree := New[ovr](ol)
+	for i := 0; i < 100; i++ {
+		tree = tree.Insert(ovr{i: i, mark: i % 7})
+	}
+	clone := tree.SortedClone(func(a, b ovr) bool { return a.mark < b.mark })
+	clone.root.balanced(t)
+	if clone.Len() != tree.Len() {
+		t.Fatalf("SortedClone has %d items, expected %d", clone.Len(), tree.Len())
+	}
+	items := treeItems(clone)
+	for i := 1; i < len(items); i++ {
+		if items[i].mark < items[i-1].mark || (items[i].mark == items[i-1].mark && items[i].i < items[i-1].i) {
+			t.Fatalf("SortedClone out of order at %d: %v %v", i, items[i-1], items[i])
+		}
+	}
+	clone = clone.Insert(ovr{i: 200, mark: 0})
+	clone.root.balanced(t)
+}
+
+func TestDeleteRange(t *testing.T) {
+	src := rand.New(rand.NewSource(13))
+	n := 200
+	tree := New[int](il, src.Perm(n)...)
+	makers := []TestMaker[int]{nil, Lt[int], Lte[int], Gt[int], Gte[int]}
+	for i := 0; i < 500; i++ {
+		var start, stop Test[int]
+		if m := makers[src.Intn(3)]; m != nil {
+			start = m(tree.Cmp(src.Intn(n+20) - 10))
+		}
+		if m := makers[[]int{0, 3, 4}[src.Intn(3)]]; m != nil {
+			stop = m(tree.Cmp(src.Intn(n+20) - 10))
+		}
+		var expect []int
+		tree.Walk(func(v int) bool {
+			if (start != nil && start(v)) || (stop != nil && stop(v)) {
+				expect = append(expect, v)
+			}
+			return true
+		})
+		res, deleted := tree.DeleteRange(start, stop)
+		res.root.balanced(t)
+		if got := treeItems(res); !reflect.DeepEqual(expect, got) || res.Len() != len(expect) {
+			t.Fatalf("DeleteRange: expected %v, got %v", expect, got)
+		}
+		if deleted != n-len(expect) {
+			t.Fatalf("DeleteRange: expected %d deleted, got %d", n-len(expect), deleted)
+		}
+	}
+	if tree.Len() != n || len(treeItems(tree)) != n {
+		t.Fatalf("DeleteRange modified the original Tree")
+	}
+}
+
+func TestSeek(t *testing.T) {
+	tree := New[int](il)
+	for i := 0; i < 100; i++ {
+		tree = tree.Insert(i * 2)
+	}
+	// Ascending, with bounds.
+	iter := tree.Iterator(Lt(tree.Cmp(20)), Gt(tree.Cmp(150))).(Seeker[int])
+	if !iter.Seek(tree.Cmp(51)) || iter.Item() != 52 {
+		t.Fatalf("Seek(51) did not land on 52")
+	}
+	if !iter.Next() || iter.Item() != 54 {
+		t.Fatalf("Next after Seek did not move to 54")
+	}
+	if !iter.Seek(tree.Cmp(100)) || iter.Item() != 100 {
+		t.Fatalf("Seek(100) did not land on 100")
+	}
+	if !iter.Seek(tree.Cmp(0)) || iter.Item() != 20 {
+		t.Fatalf("Seek(0) did not respect the start bound")
+	}
+	if iter.Seek(tree.Cmp(151)) {
+		t.Fatalf("Seek(151) ignored the stop bound, landed on %d", iter.Item())
+	}
+	if iter.Next() {
+		t.Fatalf("Failed Seek did not release the Iter")
+	}
+	// Descending.
+	iter = tree.Iterator(Lt(tree.Cmp(20)), Gt(tree.Cmp(150))).(Seeker[int])
+	if !iter.Prev() || iter.Item() != 150 {
+		t.Fatalf("Prev did not start at 150")
+	}
+	if !iter.Seek(tree.Cmp(77)) || iter.Item() != 76 {
+		t.Fatalf("Descending Seek(77) did not land on 76")
+	}
+	if !iter.Prev() || iter.Item() != 74 {
+		t.Fatalf("Prev after Seek did not move to 74")
+	}
+	if iter.Seek(tree.Cmp(10)) {
+		t.Fatalf("Descending Seek(10) ignored the start bound, landed on %d", iter.Item())
+	}
+	// OffsetAndLimit.
+	iter = tree.OffsetAndLimit(10, 20).(Seeker[int])
+	if !iter.Seek(tree.Cmp(41)) || iter.Item() != 42 {
+		t.Fatalf("Seek(41) on OffsetAndLimit did not land on 42")
+	}
+	var res []int
+	for iter.Next() {
+		res = append(res, iter.Item())
+	}
+	if !reflect.DeepEqual(res, []int{44, 46, 48, 50, 52, 54, 56, 58}) {
+		t.Fatalf("OffsetAndLimit did not stop at its limit after Seek: %v", res)
+	}
+	iter = tree.OffsetAndLimit(10, 20).(Seeker[int])
+	if !iter.Next() || !iter.Seek(tree.Cmp(-5)) || iter.Item() != 20 {
+		t.Fatalf("Seek on OffsetAndLimit did not respect the offset")
+	}
+	if iter.Seek(tree.Cmp(60)) {
+		t.Fatalf("Seek on OffsetAndLimit did not respect the limit")
+	}
+	iter = tree.All().(Seeker[int])
+	if !iter.Seek(tree.Cmp(198)) || iter.Item() != 198 || iter.Next() {
+		t.Fatalf("Seek to the last item in All failed")
+	}
+	iter = tree.All().(Seeker[int])
+	if iter.Seek(tree.Cmp(199)) {
+		t.Fatalf("Seek past the end of All succeeded")
+	}
+}
+
+func TestIteratorPrevStart(t *testing.T) {
+	tree := New[int](il, seq(0, 20)...)
+	// A first call to Prev starts at the top of the range and stops at start.
+	var res []int
+	for iter := tree.Iterator(Lt(tree.Cmp(5)), Gte(tree.Cmp(9))); iter.Prev(); {
+		res = append(res, iter.Item())
+	}
+	if !reflect.DeepEqual([]int{8, 7, 6, 5}, res) {
+		t.Fatalf("Prev: expected [8 7 6 5], got %v", res)
+	}
+	// An empty range must not yield anything, even though the largest item below stop is past start.
+	if iter := tree.Iterator(Lt(tree.Cmp(9)), Gt(tree.Cmp(5))); iter.Prev() {
+		t.Fatalf("Prev over an empty range returned %d", iter.Item())
+	}
+}
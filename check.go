@@ -0,0 +1,93 @@
+package avl
+
+import "fmt"
+
+// CheckError is returned by Tree.Check when a node in the Tree breaks one of the rules
+// an AVL Tree must follow.
+type CheckError[T any] struct {
+	// Path is the route from the root of the Tree to the failing node, with one L or R for
+	// each step to a left or right child.  It is empty for the root node, and for problems
+	// with the Tree as a whole.
+	Path string
+	Item T      // The item held by the failing node.
+	Rule string // Description of the rule that was broken.
+}
+
+func (e *CheckError[T]) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "root"
+	}
+	return fmt.Sprintf("avl: node at %s holding %v: %s", path, e.Item, e.Rule)
+}
+
+// Check verifies that t is a valid AVL Tree, returning a *CheckError describing the first problem it
+// finds or nil if there are none.  It checks that:
+//
+// * every item is in order according to the Tree's LessThan, with no equal items.
+//
+// * the height stored in every node is one more than the height of its tallest child.
+//
+// * the heights of the children of every node differ by no more than 1.
+//
+// * no node has a child from a later generation than itself, or is from a later generation than the Tree.
+//
+// * the subtree size stored in every node and the number of items in the Tree match the actual number of nodes.
+//
+// Check visits every node in the Tree, so it takes O(n) time.  It is intended for tests and debug builds.
+func (t *Tree[T]) Check() error {
+	path := make([]byte, 0, 64)
+	var walk func(n, lo, hi *node[T]) (int, error)
+	walk = func(n, lo, hi *node[T]) (int, error) {
+		if n == nil {
+			return 0, nil
+		}
+		fail := func(format string, args ...any) (int, error) {
+			return 0, &CheckError[T]{Path: string(path), Item: n.i, Rule: fmt.Sprintf(format, args...)}
+		}
+		if lo != nil && !t.less(lo.i, n.i) {
+			return fail("item does not sort after %v", lo.i)
+		}
+		if hi != nil && !t.less(n.i, hi.i) {
+			return fail("item does not sort before %v", hi.i)
+		}
+		lh, rh := n.c[l].height(), n.c[r].height()
+		if n.h() != max(lh, rh)+1 {
+			return fail("stored height %d, but children have heights %d and %d", n.h(), lh, rh)
+		}
+		if bal := int(rh) - int(lh); bal < -1 || bal > 1 {
+			return fail("balance factor %d is out of range", bal)
+		}
+		for d, dir := range [2]byte{'L', 'R'} {
+			if c := n.c[d]; c != nil && c.gen() > n.gen() {
+				return fail("%c child has generation %d, which is later than the node's %d", dir, c.gen(), n.gen())
+			}
+		}
+		path = append(path, 'L')
+		lc, err := walk(n.c[l], lo, n)
+		if err != nil {
+			return 0, err
+		}
+		path[len(path)-1] = 'R'
+		rc, err := walk(n.c[r], n, hi)
+		if err != nil {
+			return 0, err
+		}
+		path = path[:len(path)-1]
+		if n.sz != lc+rc+1 {
+			return fail("stored size %d, but subtree has %d nodes", n.sz, lc+rc+1)
+		}
+		return n.sz, nil
+	}
+	if t.root != nil && t.root.gen() > t.gen {
+		return &CheckError[T]{Item: t.root.i, Rule: fmt.Sprintf("generation %d is later than the Tree's %d", t.root.gen(), t.gen)}
+	}
+	count, err := walk(t.root, nil, nil)
+	if err != nil {
+		return err
+	}
+	if count != t.count {
+		return &CheckError[T]{Rule: fmt.Sprintf("Tree has %d items, but Len is %d", count, t.count)}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package avl
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tree := New[int](il, seq(0, 100)...)
+	for i := 0; i < 100; i += 3 {
+		tree, _, _ = tree.Delete(i)
+	}
+	if err := tree.Check(); err != nil {
+		t.Fatalf("Check of a valid Tree: %v", err)
+	}
+	if err := New[int](il).Check(); err != nil {
+		t.Fatalf("Check of an empty Tree: %v", err)
+	}
+	for _, tc := range []struct {
+		rule    string
+		corrupt func(*Tree[int])
+	}{
+		{"does not sort", func(t *Tree[int]) { t.root.c[l].c[r].i = 1000 }},
+		{"stored height", func(t *Tree[int]) { t.root.c[r].genH++ }},
+		{"balance factor", func(t *Tree[int]) { t.root.c[r] = nil }},
+		{"later than the node's", func(t *Tree[int]) { t.root.c[l].genH += 1 << hOffset }},
+		{"stored size", func(t *Tree[int]) { t.root.c[l].sz++ }},
+		{"but Len is", func(t *Tree[int]) { t.count-- }},
+	} {
+		broken := New[int](il, seq(0, 100)...)
+		tc.corrupt(broken)
+		err := broken.Check()
+		var ce *CheckError[int]
+		if !errors.As(err, &ce) || !strings.Contains(ce.Rule, tc.rule) {
+			t.Fatalf("Check: expected a %q error, got %v", tc.rule, err)
+		}
+	}
+	broken := New[int](il, seq(0, 100)...)
+	n := broken.root.c[r].c[l]
+	n.i = -1
+	err := broken.Check().(*CheckError[int])
+	if err.Path != "RL" || err.Item != -1 {
+		t.Fatalf("Check: expected the error at RL holding -1, got %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package avl
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// recentValues is the number of recently compared values a Checker keeps to test for transitivity.
+const recentValues = 8
+
+// OrderingError describes a LessThan or CompareAgainst that broke one of the rules a Tree depends on.
+type OrderingError[T any] struct {
+	Rule   string // Description of the rule that was broken.
+	Values []T    // The values that broke it.
+}
+
+func (e *OrderingError[T]) Error() string {
+	return fmt.Sprintf("avl: %s: %v", e.Rule, e.Values)
+}
+
+// Checker wraps a LessThan to verify that it is a strict weak ordering, which is what a Tree needs to
+// keep its items in order.  It is meant for tests and debug builds: create a Tree with Checker.Less
+// instead of the LessThan itself, use the Tree as usual, and then call Err to see if anything went wrong.
+//
+// Checker samples calls to Less, and for each sampled call checks that:
+//
+// * neither value is less than itself.
+//
+// * the values are not both less than each other.
+//
+// * the values are ordered consistently with the other recently sampled values, i.e. if a < b and b < c
+// then a < c, and if a and b are equal and b and c are equal then a and c are equal.
+//
+// Checker is safe for concurrent use.
+type Checker[T any] struct {
+	lt     LessThan[T]
+	every  uint64
+	calls  atomic.Uint64
+	mu     sync.Mutex
+	recent []T
+	next   int
+	err    error
+}
+
+// Checked returns a Checker that checks one of every sampleEvery calls to lt.
+// If sampleEvery is less than 2, every call is checked.
+func Checked[T any](lt LessThan[T], sampleEvery int) *Checker[T] {
+	return &Checker[T]{lt: lt, every: uint64(max(sampleEvery, 1))}
+}
+
+// Err returns an *OrderingError describing the first problem the Checker found, or nil if it has not found any.
+func (c *Checker[T]) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// fail records a problem if one has not already been found.  c.mu must be held.
+func (c *Checker[T]) fail(rule string, values ...T) {
+	if c.err == nil {
+		c.err = &OrderingError[T]{Rule: rule, Values: values}
+	}
+}
+
+// sample returns true if the current call should be checked.
+func (c *Checker[T]) sample() bool {
+	return c.calls.Add(1)%c.every == 0
+}
+
+// Less calls the wrapped LessThan, checking the call if it is sampled.  Pass it to New, or
+// anywhere else that needs a LessThan.
+func (c *Checker[T]) Less(a, b T) bool {
+	res := c.lt(a, b)
+	if c.sample() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.check(a, b, res)
+	}
+	return res
+}
+
+// equal returns true if neither a nor b is less than the other.
+func (c *Checker[T]) equal(a, b T) bool {
+	return !c.lt(a, b) && !c.lt(b, a)
+}
+
+// check checks a call to lt(a, b) that returned ab against the rules.  c.mu must be held.
+func (c *Checker[T]) check(a, b T, ab bool) {
+	if c.err != nil {
+		return
+	}
+	for _, v := range [2]T{a, b} {
+		if c.lt(v, v) {
+			c.fail("value is less than itself", v)
+			return
+		}
+	}
+	ba := c.lt(b, a)
+	if ab && ba {
+		c.fail("values are less than each other", a, b)
+		return
+	}
+	for _, x := range c.recent {
+		c.checkTriple(a, b, x)
+		c.checkTriple(b, a, x)
+		if c.err != nil {
+			return
+		}
+	}
+	for _, v := range [2]T{a, b} {
+		if len(c.recent) < recentValues {
+			c.recent = append(c.recent, v)
+		} else {
+			c.recent[c.next] = v
+			c.next = (c.next + 1) % recentValues
+		}
+	}
+}
+
+// checkTriple checks that the ordering of a, b and x is transitive.  c.mu must be held.
+func (c *Checker[T]) checkTriple(a, b, x T) {
+	switch {
+	case c.lt(a, b) && c.lt(b, x) && !c.lt(a, x):
+		c.fail("ordering is not transitive: first < second and second < third, but not first < third", a, b, x)
+	case c.lt(x, a) && c.lt(a, b) && !c.lt(x, b):
+		c.fail("ordering is not transitive: first < second and second < third, but not first < third", x, a, b)
+	case c.equal(a, b) && c.equal(b, x) && !c.equal(a, x):
+		c.fail("equality is not transitive: first = second and second = third, but not first = third", a, b, x)
+	}
+}
+
+// Compare wraps a CompareAgainst for reference so that every call checks that it returns Less, Equal,
+// or Greater, and sampled calls check that it agrees with the wrapped LessThan.  It is for checking
+// CompareAgainst functions written by hand rather than made by Tree.Cmp.  A CompareAgainst that returns
+// anything else will still make Get panic, so call Err after recovering to find out why.
+func (c *Checker[T]) Compare(reference T, cmp CompareAgainst[T]) CompareAgainst[T] {
+	return func(item T) int {
+		res := cmp(item)
+		switch {
+		case res < Less || res > Greater:
+			c.mu.Lock()
+			c.fail(fmt.Sprintf("CompareAgainst returned %d, which is not Less, Equal, or Greater", res), reference, item)
+			c.mu.Unlock()
+		case c.sample():
+			expect := Equal
+			if c.lt(item, reference) {
+				expect = Less
+			} else if c.lt(reference, item) {
+				expect = Greater
+			}
+			if res != expect {
+				c.mu.Lock()
+				c.fail(fmt.Sprintf("CompareAgainst returned %d comparing the reference to the item, but LessThan says %d", res, expect), reference, item)
+				c.mu.Unlock()
+			}
+		}
+		return res
+	}
+}
@@ -0,0 +1,55 @@
+package avl
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestChecked(t *testing.T) {
+	src := rand.New(rand.NewSource(47))
+	good := Checked[int](il, 3)
+	tree := New[int](good.Less, src.Perm(500)...)
+	for i := 0; i < 500; i++ {
+		if _, found := tree.Get(good.Compare(i, tree.Cmp(i))); !found {
+			t.Fatalf("Get(%d) failed", i)
+		}
+	}
+	if err := good.Err(); err != nil {
+		t.Fatalf("Checker found a problem with a valid LessThan: %v", err)
+	}
+	for _, tc := range []struct {
+		rule string
+		lt   LessThan[int]
+	}{
+		{"less than itself", func(a, b int) bool { return a <= b }},
+		{"not transitive", func(a, b int) bool { return (b%3-a%3+3)%3 == 1 }},
+		{"equality is not transitive", func(a, b int) bool { return a < b-1 }},
+	} {
+		c := Checked(tc.lt, 1)
+		func() {
+			// A broken LessThan may well make the Tree panic.
+			defer func() { recover() }()
+			New[int](c.Less, src.Perm(100)...)
+		}()
+		var oe *OrderingError[int]
+		if err := c.Err(); !errors.As(err, &oe) || !strings.Contains(oe.Rule, tc.rule) {
+			t.Fatalf("Checker: expected a %q error, got %v", tc.rule, err)
+		}
+	}
+	c := Checked[int](il, 1)
+	tree = New[int](c.Less, 1, 2, 3)
+	func() {
+		defer func() { recover() }()
+		tree.Get(c.Compare(1, func(v int) int { return (v - 1) * 2 }))
+	}()
+	if err := c.Err(); err == nil || !strings.Contains(err.Error(), "returned") {
+		t.Fatalf("Compare: expected an out of range error, got %v", err)
+	}
+	c = Checked[int](il, 1)
+	tree.Get(c.Compare(2, func(v int) int { return Less }))
+	if err := c.Err(); err == nil || !strings.Contains(err.Error(), "LessThan says") {
+		t.Fatalf("Compare: expected a disagreement error, got %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+package avl
+
+// OpCost describes the work done by a single operation that modified a Tree.
+type OpCost struct {
+	Copied    int // Nodes copied because they were shared with an older Tree.
+	Allocated int // New nodes allocated to hold items.
+	Rotations int // Single rotations done to keep the Tree balanced.  A double rotation counts as 2.
+	Compares  int // Comparisons made between items.  WithCosts describes how they are counted.
+}
+
+// WithCosts returns a Fork of t that calls report with the OpCost of every operation that modifies it.
+// Trees made from the returned Tree by Insert, Delete and the other functions that return a modified
+// copy inherit report.  Bulk operations such as InsertWith and DeleteFrom call report once with their
+// total cost, and a Transient calls it once when it is persisted.  Passing a nil report turns reporting off.
+// Costs are only counted for Trees that have a report function, so other Trees do no extra work.
+//
+// Compares counts calls to the Tree's LessThan, except that operations which divide a Tree up with a
+// CompareAgainst or Test, such as Split, DeleteRange and the set operations, count each call to it
+// as a single comparison instead.  Join and Join3 count the comparisons made to check their arguments
+// are in order, and FromSorted, UnmarshalJSON and GobDecode count the ones made to check the items
+// they are given are sorted.
+//
+// report is called from the goroutine doing the operation, after the operation is finished.
+func (t *Tree[T]) WithCosts(report func(OpCost)) *Tree[T] {
+	res := t.Fork()
+	res.cost = report
+	return res
+}
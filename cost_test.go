@@ -0,0 +1,54 @@
+package avl
+
+import "testing"
+
+func TestWithCosts(t *testing.T) {
+	var costs []OpCost
+	tree := New[int](il, seq(0, 1023)...).WithCosts(func(c OpCost) { costs = append(costs, c) })
+	next := tree.Insert(2000)
+	if len(costs) != 1 {
+		t.Fatalf("Insert reported %d costs", len(costs))
+	}
+	c := costs[0]
+	h := int(tree.root.h())
+	// The path from the root to the new leaf is copied, and the leaf is allocated.
+	if c.Allocated != 1 || c.Copied < h-1 || c.Copied > h+2 || c.Compares < h || c.Compares > 2*h {
+		t.Fatalf("Insert: got %+v for a Tree of height %d", c, h)
+	}
+	costs = nil
+	// Inserting in order forces rotations, and nodes copied once are not copied again.
+	next = next.InsertWith(func(ins func(int)) {
+		for i := 3000; i < 3100; i++ {
+			ins(i)
+		}
+	})
+	if len(costs) != 1 || costs[0].Allocated != 100 || costs[0].Rotations == 0 || costs[0].Copied > 2*h {
+		t.Fatalf("InsertWith: got %+v", costs)
+	}
+	costs = nil
+	next, _, _ = next.Delete(3050)
+	next = next.DeleteFrom(New[int](il, 3000, 3001).All())
+	if len(costs) != 2 || costs[0].Allocated != 0 || costs[0].Copied == 0 {
+		t.Fatalf("Delete: got %+v", costs)
+	}
+	if err := next.Check(); err != nil {
+		t.Fatal(err)
+	}
+	// Operations that split the Tree count a comparison for every node split looks at.
+	costs = nil
+	next.Split(next.Cmp(500))
+	next.DeleteRange(Lt(next.Cmp(100)), Gte(next.Cmp(200)))
+	next.Union(New[int](il, 5000, 5001))
+	if len(costs) != 3 || costs[0].Compares == 0 || costs[0].Compares > h+2 || costs[1].Compares == 0 || costs[2].Compares == 0 {
+		t.Fatalf("Split, DeleteRange and Union: got %+v", costs)
+	}
+	costs = nil
+	if err := next.UnmarshalJSON([]byte(`[1,2,3,4]`)); err != nil || len(costs) != 1 || costs[0].Compares != 3 {
+		t.Fatalf("UnmarshalJSON: got %+v, %v", costs, err)
+	}
+	costs = nil
+	next.WithCosts(nil).Insert(5000)
+	if len(costs) != 0 {
+		t.Fatalf("WithCosts(nil) still reported %+v", costs)
+	}
+}
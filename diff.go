@@ -0,0 +1,150 @@
+package avl
+
+// ChangeKind describes how an item changed between two versions of a Tree.
+type ChangeKind int
+
+const (
+	Added   ChangeKind = iota // The item is only in the new Tree.
+	Removed                   // The item is only in the old Tree.
+	Changed                   // Equal items are in both Trees, but eq says they differ.
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "Added"
+	case Removed:
+		return "Removed"
+	case Changed:
+		return "Changed"
+	default:
+		return "Unknown"
+	}
+}
+
+// Change is a single difference between two versions of a Tree.
+// Old is the zero value for Added items, and New is the zero value for Removed items.
+type Change[T any] struct {
+	Kind     ChangeKind
+	Old, New T
+}
+
+// diffFrame is a pending part of a Tree that diffSide has not walked over yet.
+// It is either a whole subtree, or just the item held by a single node.
+type diffFrame[T any] struct {
+	n     *node[T]
+	whole bool
+}
+
+// diffSide keeps track of the part of a Tree that diff has not walked over yet.
+// The top of the stack holds the smallest items.
+type diffSide[T any] []diffFrame[T]
+
+func (s *diffSide[T]) push(n *node[T], whole bool) {
+	if n != nil {
+		*s = append(*s, diffFrame[T]{n: n, whole: whole})
+	}
+}
+
+func (s diffSide[T]) top() diffFrame[T] {
+	return s[len(s)-1]
+}
+
+func (s *diffSide[T]) pop() {
+	(*s)[len(*s)-1] = diffFrame[T]{}
+	*s = (*s)[:len(*s)-1]
+}
+
+// expand replaces the whole subtree at the top of the stack with its left subtree,
+// its item, and its right subtree.
+func (s *diffSide[T]) expand() {
+	n := s.top().n
+	s.pop()
+	s.push(n.c[r], true)
+	s.push(n, false)
+	s.push(n.c[l], true)
+}
+
+// next expands the top of the stack until it holds a single item, then pops and returns it.
+func (s *diffSide[T]) next() T {
+	for s.top().whole {
+		s.expand()
+	}
+	n := s.top().n
+	s.pop()
+	return n.i
+}
+
+// diff walks over old and new in order, calling fn with each Change it finds until fn returns false.
+// It is a merge walk over both Trees that skips any subtree that is at the same point in both walks.
+// Since Forked Trees share every subtree that did not change, that keeps the walk close to the
+// number of changed items when old and new share most of their nodes, while still working
+// on Trees that share nothing at all.
+func diff[T any](old, new *Tree[T], eq func(a, b T) bool, fn func(Change[T]) bool) {
+	var a, b diffSide[T]
+	a.push(old.root, true)
+	b.push(new.root, true)
+	less := new.less
+	for len(a) > 0 && len(b) > 0 {
+		fa, fb := a.top(), b.top()
+		switch {
+		case fa.whole && fb.whole && fa.n == fb.n:
+			// Shared subtree, nothing in it could have changed.
+			a.pop()
+			b.pop()
+			continue
+		case fa.whole && (!fb.whole || fa.n.h() >= fb.n.h()):
+			// Break down the taller subtree first, to give shared subtrees a chance to line up.
+			a.expand()
+			continue
+		case fb.whole:
+			b.expand()
+			continue
+		}
+		switch {
+		case less(fa.n.i, fb.n.i):
+			a.pop()
+			if !fn(Change[T]{Kind: Removed, Old: fa.n.i}) {
+				return
+			}
+		case less(fb.n.i, fa.n.i):
+			b.pop()
+			if !fn(Change[T]{Kind: Added, New: fb.n.i}) {
+				return
+			}
+		default:
+			a.pop()
+			b.pop()
+			if fa.n != fb.n && eq != nil && !eq(fa.n.i, fb.n.i) {
+				if !fn(Change[T]{Kind: Changed, Old: fa.n.i, New: fb.n.i}) {
+					return
+				}
+			}
+		}
+	}
+	for len(a) > 0 {
+		if !fn(Change[T]{Kind: Removed, Old: a.next()}) {
+			return
+		}
+	}
+	for len(b) > 0 {
+		if !fn(Change[T]{Kind: Added, New: b.next()}) {
+			return
+		}
+	}
+}
+
+// Diff returns the Changes needed to turn old into new, in ascending order.
+// Items that are in both Trees are passed to eq, and are reported as Changed if eq returns false.
+// If eq is nil, items that are in both Trees are never reported.  old and new must be ordered by the same LessThan.
+//
+// Diff skips over subtrees that old and new share, so when new is a modified Fork of old (or the
+// other way around), it takes time proportional to the number of changes rather than the size
+// of the Trees.  Trees that do not share any nodes are compared with a merge walk.
+func Diff[T any](old, new *Tree[T], eq func(a, b T) bool) (res []Change[T]) {
+	diff(old, new, eq, func(c Change[T]) bool {
+		res = append(res, c)
+		return true
+	})
+	return
+}
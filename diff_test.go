@@ -0,0 +1,98 @@
+package avl
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func ovrEq(a, b ovr) bool { return a == b }
+
+// naiveDiff computes what Diff should return by comparing every item.
+func naiveDiff(old, new *Tree[ovr]) (res []Change[ovr]) {
+	om, nm := map[int]ovr{}, map[int]ovr{}
+	for v := range old.Values() {
+		om[v.i] = v
+	}
+	for v := range new.Values() {
+		nm[v.i] = v
+	}
+	for i := -1000; i < 2000; i++ {
+		o, inOld := om[i]
+		n, inNew := nm[i]
+		switch {
+		case inOld && !inNew:
+			res = append(res, Change[ovr]{Kind: Removed, Old: o})
+		case inNew && !inOld:
+			res = append(res, Change[ovr]{Kind: Added, New: n})
+		case inOld && inNew && o != n:
+			res = append(res, Change[ovr]{Kind: Changed, Old: o, New: n})
+		}
+	}
+	return
+}
+
+func TestDiff(t *testing.T) {
+	src := rand.New(rand.NewSource(29))
+	base := New[ovr](ol)
+	for _, v := range src.Perm(1000) {
+		base = base.Insert(ovr{i: v})
+	}
+	for round := 0; round < 50; round++ {
+		next := base
+		for j := 0; j < src.Intn(20); j++ {
+			switch src.Intn(3) {
+			case 0:
+				next = next.Insert(ovr{i: src.Intn(1200), mark: round})
+			case 1:
+				next, _, _ = next.Delete(ovr{i: src.Intn(1200)})
+			case 2:
+				next = next.Insert(ovr{i: 1000 + src.Intn(100)})
+			}
+		}
+		expect := naiveDiff(base, next)
+		if got := Diff(base, next, ovrEq); !reflect.DeepEqual(expect, got) {
+			t.Fatalf("Diff: expected %v, got %v", expect, got)
+		}
+		base = next
+	}
+	// Trees that share nothing at all.
+	other := New[ovr](ol)
+	for v := range base.Values() {
+		if v.i%3 != 0 {
+			other = other.Insert(ovr{i: v.i, mark: v.mark + v.i%2})
+		}
+	}
+	other = other.Insert(ovr{i: -5})
+	expect := naiveDiff(base, other)
+	if got := Diff(base, other, ovrEq); !reflect.DeepEqual(expect, got) {
+		t.Fatalf("Diff of unrelated Trees: expected %v, got %v", expect, got)
+	}
+	if got := Diff(New[ovr](ol), other, nil); len(got) != other.Len() {
+		t.Fatalf("Diff from an empty Tree: expected %d changes, got %d", other.Len(), len(got))
+	}
+	if got := Diff(base, base, ovrEq); len(got) != 0 {
+		t.Fatalf("Diff of a Tree against itself found %v", got)
+	}
+}
+
+func TestDiffSkipsShared(t *testing.T) {
+	base := New[ovr](ol)
+	for i := 0; i < 1<<16; i++ {
+		base = base.Insert(ovr{i: i})
+	}
+	next := base.Insert(ovr{i: 500, mark: 1})
+	next, _, _ = next.Delete(ovr{i: 40000})
+	calls := 0
+	got := Diff(base, next, func(a, b ovr) bool {
+		calls++
+		return a == b
+	})
+	expect := []Change[ovr]{{Kind: Changed, Old: ovr{500, 0}, New: ovr{500, 1}}, {Kind: Removed, Old: ovr{i: 40000}}}
+	if !reflect.DeepEqual(expect, got) {
+		t.Fatalf("Diff: expected %v, got %v", expect, got)
+	}
+	if calls > 200 {
+		t.Fatalf("Diff compared %d items, shared subtrees were not skipped", calls)
+	}
+}
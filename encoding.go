@@ -0,0 +1,88 @@
+package avl
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+)
+
+// ErrNoLessThan is returned when decoding into a Tree that was not created with a LessThan.
+// Functions cannot be encoded, so the LessThan for a decoded Tree must come from the caller.
+var ErrNoLessThan = errors.New("Tree has no LessThan to decode with")
+
+// items returns the contents of t as a slice in ascending order.
+func (t *Tree[T]) items() []T {
+	res := make([]T, 0, t.count)
+	for v := range t.Values() {
+		res = append(res, v)
+	}
+	return res
+}
+
+// decodeSorted makes items the contents of t.  Encoded Trees never contain equal items,
+// so unlike FromSorted, decodeSorted treats runs of equal items as being out of order.
+func (t *Tree[T]) decodeSorted(items []T) error {
+	if t.less == nil {
+		return ErrNoLessThan
+	}
+	return t.fromSorted(items, true)
+}
+
+// MarshalJSON encodes t as a JSON array holding its items in ascending order.
+func (t *Tree[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.items())
+}
+
+// UnmarshalJSON replaces the contents of t with the items in a JSON array.
+// t must have been created with a LessThan, and the items must be in ascending order according to it.
+// Most callers should use DecodeJSON instead.
+func (t *Tree[T]) UnmarshalJSON(data []byte) error {
+	var items []T
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+	return t.decodeSorted(items)
+}
+
+// DecodeJSON creates a new Tree ordered by lt from a JSON array created by MarshalJSON.
+// The Tree is built in O(n) time.  If the items are not in ascending order according to lt,
+// DecodeJSON returns an error wrapping ErrUnsorted.
+func DecodeJSON[T any](lt LessThan[T], data []byte) (*Tree[T], error) {
+	res := New[T](lt)
+	if err := res.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GobEncode encodes t as a gob-encoded slice holding its items in ascending order.
+func (t *Tree[T]) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(t.items()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode replaces the contents of t with the items encoded by GobEncode.
+// t must have been created with a LessThan, and the items must be in ascending order according to it.
+// Most callers should use DecodeGob instead.
+func (t *Tree[T]) GobDecode(data []byte) error {
+	var items []T
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&items); err != nil {
+		return err
+	}
+	return t.decodeSorted(items)
+}
+
+// DecodeGob creates a new Tree ordered by lt from data created by GobEncode.
+// The Tree is built in O(n) time.  If the items are not in ascending order according to lt,
+// DecodeGob returns an error wrapping ErrUnsorted.
+func DecodeGob[T any](lt LessThan[T], data []byte) (*Tree[T], error) {
+	res := New[T](lt)
+	if err := res.GobDecode(data); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
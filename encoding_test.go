@@ -0,0 +1,74 @@
+package avl
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	tree := New[int](il, 5, 3, 9, 1, 7)
+	buf, err := json.Marshal(tree)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if string(buf) != "[1,3,5,7,9]" {
+		t.Fatalf("Marshal: got %s", buf)
+	}
+	res, err := DecodeJSON[int](il, buf)
+	if err != nil {
+		t.Fatalf("DecodeJSON: %v", err)
+	}
+	res.root.balanced(t)
+	if !reflect.DeepEqual(treeItems(tree), treeItems(res)) {
+		t.Fatalf("DecodeJSON: expected %v, got %v", treeItems(tree), treeItems(res))
+	}
+	if buf, _ = json.Marshal(New[int](il)); string(buf) != "[]" {
+		t.Fatalf("Marshal of an empty Tree: got %s", buf)
+	}
+	// Decoding with a different ordering than the Tree was encoded with should fail.
+	if _, err = DecodeJSON[int](func(a, b int) bool { return a > b }, []byte("[1,3,5]")); !errors.Is(err, ErrUnsorted) {
+		t.Fatalf("DecodeJSON of reversed items: expected ErrUnsorted, got %v", err)
+	}
+	if _, err = DecodeJSON[int](il, []byte("[1,3,3]")); !errors.Is(err, ErrUnsorted) {
+		t.Fatalf("DecodeJSON of duplicate items: expected ErrUnsorted, got %v", err)
+	}
+	// Trees embedded in other values can be decoded if they already have a LessThan.
+	var resp struct{ Items *Tree[string] }
+	if err = json.Unmarshal([]byte(`{"Items":["a","b"]}`), &resp); !errors.Is(err, ErrNoLessThan) {
+		t.Fatalf("Unmarshal into a Tree without a LessThan: expected ErrNoLessThan, got %v", err)
+	}
+	resp.Items = New[string](sl)
+	if err = json.Unmarshal([]byte(`{"Items":["a","b"]}`), &resp); err != nil || resp.Items.Len() != 2 {
+		t.Fatalf("Unmarshal into a Tree: got %v %v", treeItems(resp.Items), err)
+	}
+}
+
+func TestGob(t *testing.T) {
+	tree := New[int](il, seq(0, 1000)...)
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(tree); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	res := New[int](il)
+	if err := gob.NewDecoder(&buf).Decode(res); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	res.root.balanced(t)
+	if !reflect.DeepEqual(seq(0, 1000), treeItems(res)) {
+		t.Fatalf("Decode did not preserve the contents")
+	}
+	data, err := tree.GobEncode()
+	if err != nil {
+		t.Fatalf("GobEncode: %v", err)
+	}
+	if res, err = DecodeGob[int](il, data); err != nil || res.Len() != 1000 {
+		t.Fatalf("DecodeGob: got %d items, %v", res.Len(), err)
+	}
+	if _, err = DecodeGob[int](func(a, b int) bool { return a > b }, data); !errors.Is(err, ErrUnsorted) {
+		t.Fatalf("DecodeGob of reversed items: expected ErrUnsorted, got %v", err)
+	}
+}
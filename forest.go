@@ -0,0 +1,163 @@
+package avl
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrConflict is returned by Txn.Commit when a tree in the Forest was changed by another
+// transaction that committed first.
+var ErrConflict = errors.New("transaction conflict")
+
+const (
+	txnDone       = `Txn used after Commit or Rollback`
+	noSuchTree    = `No tree with that name in the Forest`
+	nilForestTree = `Forest trees must not be nil`
+)
+
+// forestState is one version of the trees in a Forest.  It is never modified after it is published.
+type forestState[T any] struct {
+	trees   map[string]*Tree[T]
+	version uint64
+}
+
+// Forest holds a set of named Trees that are updated together, such as a primary index and
+// secondary orderings of the same items made with SortBy.  Readers get a consistent view of all the
+// Trees with Snapshot, and writers update them with transactions started by Begin.
+//
+// Forest is safe for concurrent use.
+type Forest[T any] struct {
+	p atomic.Pointer[forestState[T]]
+}
+
+// NewForest creates a new Forest holding trees.
+func NewForest[T any](trees map[string]*Tree[T]) *Forest[T] {
+	res := &Forest[T]{}
+	st := &forestState[T]{trees: make(map[string]*Tree[T], len(trees))}
+	for name, t := range trees {
+		if t == nil {
+			panic(nilForestTree)
+		}
+		st.trees[name] = t
+	}
+	res.p.Store(st)
+	return res
+}
+
+// Tree returns the current version of the named Tree, or nil if there is no such Tree.
+func (f *Forest[T]) Tree(name string) *Tree[T] {
+	return f.p.Load().trees[name]
+}
+
+// Snapshot returns the current version of every Tree in the Forest, along with the number of
+// transactions that have been committed to the Forest.
+func (f *Forest[T]) Snapshot() (trees map[string]*Tree[T], version uint64) {
+	st := f.p.Load()
+	trees = make(map[string]*Tree[T], len(st.trees))
+	for name, t := range st.trees {
+		trees[name] = t
+	}
+	return trees, st.version
+}
+
+// Txn stages changes to the Trees in a Forest so that they can be published together.
+// A Txn sees the Trees as they were when it began, plus its own changes, no matter what
+// other transactions commit in the meantime.  A Txn is not safe for concurrent use.
+type Txn[T any] struct {
+	f      *Forest[T]
+	base   *forestState[T]
+	staged map[string]*Tree[T]
+}
+
+// Begin starts a new transaction against the current versions of the Trees in f.
+func (f *Forest[T]) Begin() *Txn[T] {
+	return &Txn[T]{f: f, base: f.p.Load(), staged: map[string]*Tree[T]{}}
+}
+
+// tree returns the Tree name as the transaction currently sees it, panicking if there is no such Tree.
+func (tx *Txn[T]) tree(name string) *Tree[T] {
+	if tx.staged == nil {
+		panic(txnDone)
+	}
+	if t, ok := tx.staged[name]; ok {
+		return t
+	}
+	if t, ok := tx.base.trees[name]; ok {
+		return t
+	}
+	panic(noSuchTree)
+}
+
+// Tree returns the named Tree with any changes the transaction has staged for it.
+// It panics if there is no such Tree.
+func (tx *Txn[T]) Tree(name string) *Tree[T] {
+	return tx.tree(name)
+}
+
+// Set stages t as the new version of the named Tree, adding it to the Forest if needed.
+func (tx *Txn[T]) Set(name string, t *Tree[T]) {
+	if tx.staged == nil {
+		panic(txnDone)
+	}
+	if t == nil {
+		panic(nilForestTree)
+	}
+	tx.staged[name] = t
+}
+
+// Insert stages inserting items into the named Tree.
+func (tx *Txn[T]) Insert(name string, items ...T) {
+	tx.staged[name] = tx.tree(name).Insert(items...)
+}
+
+// Delete stages deleting items from the named Tree, and returns the number of items that will be deleted.
+func (tx *Txn[T]) Delete(name string, items ...T) (deleted int) {
+	tx.staged[name], deleted = tx.tree(name).DeleteItems(items...)
+	return
+}
+
+// Commit publishes every Tree the transaction changed at once, and ends the transaction.  If another
+// transaction that committed after this one began changed any Tree this one started with, or added a
+// Tree this one also adds, nothing is published and Commit returns an error wrapping ErrConflict.
+// That holds even for Trees this transaction did not change, since its staged changes may depend on
+// what it read from them.
+func (tx *Txn[T]) Commit() error {
+	if tx.staged == nil {
+		panic(txnDone)
+	}
+	defer tx.Rollback()
+	if len(tx.staged) == 0 {
+		return nil
+	}
+	for {
+		cur := tx.f.p.Load()
+		for name, t := range tx.base.trees {
+			if cur.trees[name] != t {
+				return fmt.Errorf("%w: %q was changed by another transaction", ErrConflict, name)
+			}
+		}
+		for name := range tx.staged {
+			if _, ok := tx.base.trees[name]; !ok && cur.trees[name] != nil {
+				return fmt.Errorf("%w: %q was added by another transaction", ErrConflict, name)
+			}
+		}
+		next := &forestState[T]{trees: make(map[string]*Tree[T], len(cur.trees)), version: cur.version + 1}
+		for name, t := range cur.trees {
+			next.trees[name] = t
+		}
+		for name, t := range tx.staged {
+			next.trees[name] = t
+		}
+		if tx.f.p.CompareAndSwap(cur, next) {
+			return nil
+		}
+	}
+}
+
+// Rollback discards the changes the transaction staged and ends it.
+// It is safe to call Rollback on a transaction that has already ended.
+func (tx *Txn[T]) Rollback() {
+	tx.staged = nil
+	tx.base = nil
+}
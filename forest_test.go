@@ -0,0 +1,91 @@
+package avl
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestForest(t *testing.T) {
+	byMark := func(a, b ovr) bool { return a.mark < b.mark || (a.mark == b.mark && a.i < b.i) }
+	f := NewForest(map[string]*Tree[ovr]{"id": New[ovr](ol), "mark": New[ovr](byMark)})
+	tx := f.Begin()
+	tx.Insert("id", ovr{1, 3}, ovr{2, 1})
+	tx.Insert("mark", ovr{1, 3}, ovr{2, 1})
+	if f.Tree("id").Len() != 0 || tx.Tree("id").Len() != 2 {
+		t.Fatalf("Staged changes were visible outside the transaction")
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	trees, version := f.Snapshot()
+	if version != 1 || !reflect.DeepEqual([]ovr{{2, 1}, {1, 3}}, treeItems(trees["mark"])) {
+		t.Fatalf("Commit: got version %d, %v", version, treeItems(trees["mark"]))
+	}
+	// Any Tree changing after a transaction began makes it conflict, even one it did not change.
+	a, b, c, d := f.Begin(), f.Begin(), f.Begin(), f.Begin()
+	a.Insert("id", ovr{i: 3})
+	b.Set("extra", New[ovr](ol, ovr{i: 9}))
+	c.Delete("mark", ovr{i: 1, mark: 3})
+	d.Set("extra", New[ovr](ol))
+	if err := a.Commit(); err != nil {
+		t.Fatalf("Commit of a: %v", err)
+	}
+	if err := b.Commit(); !errors.Is(err, ErrConflict) {
+		t.Fatalf("Commit of b: expected ErrConflict, got %v", err)
+	}
+	if err := c.Commit(); !errors.Is(err, ErrConflict) {
+		t.Fatalf("Commit of c: expected ErrConflict, got %v", err)
+	}
+	b = f.Begin()
+	b.Set("extra", New[ovr](ol, ovr{i: 9}))
+	if err := b.Commit(); err != nil {
+		t.Fatalf("Commit of b: %v", err)
+	}
+	// A Tree added by another transaction conflicts as well.
+	if err := d.Commit(); !errors.Is(err, ErrConflict) {
+		t.Fatalf("Commit of d: expected ErrConflict, got %v", err)
+	}
+	if f.Tree("id").Len() != 3 || f.Tree("mark").Len() != 2 || f.Tree("extra").Len() != 1 {
+		t.Fatalf("Conflicting Commit published changes")
+	}
+	tx = f.Begin()
+	tx.Delete("id", ovr{i: 1})
+	tx.Rollback()
+	if f.Tree("id").Len() != 3 {
+		t.Fatalf("Rollback published changes")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Using a Txn after Rollback did not panic")
+		}
+	}()
+	tx.Insert("id", ovr{i: 4})
+}
+
+func TestForestConcurrent(t *testing.T) {
+	f := NewForest(map[string]*Tree[int]{"a": New[int](il), "b": New[int](il)})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				for {
+					tx := f.Begin()
+					tx.Insert("a", i*100+j)
+					tx.Insert("b", -(i*100 + j))
+					if err := tx.Commit(); err == nil {
+						break
+					}
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	trees, version := f.Snapshot()
+	if version != 800 || trees["a"].Len() != 800 || trees["b"].Len() != 800 {
+		t.Fatalf("Concurrent commits: got version %d, %d and %d items", version, trees["a"].Len(), trees["b"].Len())
+	}
+}
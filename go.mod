@@ -1,3 +1,3 @@
 module github.com/VictorLowther/avl
 
-go 1.18
+go 1.23
@@ -0,0 +1,175 @@
+package avl
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Version is a Tree committed to a History, along with the generation History gave it
+// and the time it was committed.
+type Version[T any] struct {
+	Gen  uint64
+	Time time.Time
+	Tree *Tree[T]
+}
+
+// Retention controls which versions a History keeps.  A version is kept if any of these rules
+// say to keep it.  The current version is always kept, and the zero Retention keeps everything.
+type Retention struct {
+	KeepLast int           // Keep the KeepLast most recent versions.
+	MaxAge   time.Duration // Keep versions that have been current at some point within the last MaxAge.
+}
+
+// histEntry is a Version along with the time it was replaced by the next one.
+type histEntry[T any] struct {
+	Version[T]
+	replaced time.Time
+}
+
+// History records versions of a Tree as they are committed, and allows looking them up later by
+// generation or by time.  Generations are assigned by History starting from 0 for the Tree passed
+// to NewHistory, and go up by one with every Commit.  Versions that the Retention no longer calls for
+// and that are not pinned are pruned, which drops History's reference to them so that any nodes only
+// they use can be garbage collected.
+//
+// History is safe for concurrent use.
+type History[T any] struct {
+	mu      sync.RWMutex
+	entries []histEntry[T] // Retained versions in ascending order of Gen.  The last one is current.
+	pinned  map[uint64]int
+	keep    Retention
+	now     func() time.Time
+}
+
+// NewHistory creates a new History with t as its first version, which will keep versions according to keep.
+func NewHistory[T any](t *Tree[T], keep Retention) *History[T] {
+	res := &History[T]{pinned: map[uint64]int{}, keep: keep, now: time.Now}
+	res.entries = append(res.entries, histEntry[T]{Version: Version[T]{Tree: t, Time: res.now()}})
+	return res
+}
+
+// Commit records t as the new current version, prunes any versions that should no longer
+// be kept, and returns the generation of t.
+func (h *History[T]) Commit(t *Tree[T]) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := h.now()
+	cur := &h.entries[len(h.entries)-1]
+	cur.replaced = now
+	gen := cur.Gen + 1
+	h.entries = append(h.entries, histEntry[T]{Version: Version[T]{Gen: gen, Time: now, Tree: t}})
+	h.prune(now)
+	return gen
+}
+
+// Current returns the current version.
+func (h *History[T]) Current() Version[T] {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.entries[len(h.entries)-1].Version
+}
+
+// find returns the index of the entry for gen, or -1 if it is not retained.
+func (h *History[T]) find(gen uint64) int {
+	i := sort.Search(len(h.entries), func(i int) bool { return h.entries[i].Gen >= gen })
+	if i == len(h.entries) || h.entries[i].Gen != gen {
+		return -1
+	}
+	return i
+}
+
+// At returns the Tree that was committed as gen, and whether it is still retained.
+func (h *History[T]) At(gen uint64) (*Tree[T], bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if i := h.find(gen); i >= 0 {
+		return h.entries[i].Tree, true
+	}
+	return nil, false
+}
+
+// AsOf returns the version that was current at when, and whether it is still retained.
+func (h *History[T]) AsOf(when time.Time) (Version[T], bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	i := sort.Search(len(h.entries), func(i int) bool { return h.entries[i].Time.After(when) }) - 1
+	if i < 0 {
+		return Version[T]{}, false
+	}
+	// If the versions committed after entries[i] were pruned, it may not have been current at when.
+	if e := h.entries[i]; i == len(h.entries)-1 || when.Before(e.replaced) {
+		return e.Version, true
+	}
+	return Version[T]{}, false
+}
+
+// Versions returns all the retained versions in the order they were committed.
+func (h *History[T]) Versions() []Version[T] {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	res := make([]Version[T], len(h.entries))
+	for i := range h.entries {
+		res[i] = h.entries[i].Version
+	}
+	return res
+}
+
+// Pin keeps gen from being pruned until a matching call to Unpin.  Pins nest, so a version
+// that has been pinned twice must be unpinned twice.  Pin returns false if gen has already been pruned.
+func (h *History[T]) Pin(gen uint64) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.find(gen) < 0 {
+		return false
+	}
+	h.pinned[gen]++
+	return true
+}
+
+// Unpin releases a pin on gen taken by Pin, pruning it if nothing else is keeping it.
+func (h *History[T]) Unpin(gen uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	switch h.pinned[gen] {
+	case 0:
+		return
+	case 1:
+		delete(h.pinned, gen)
+	default:
+		h.pinned[gen]--
+	}
+	h.prune(h.now())
+}
+
+// Prune drops any versions that the Retention no longer calls for and that are not pinned.
+// Commit and Unpin prune automatically, but versions that only MaxAge was keeping will not
+// be pruned as they age unless Prune is called.
+func (h *History[T]) Prune() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.prune(h.now())
+}
+
+func (h *History[T]) prune(now time.Time) {
+	if h.keep.KeepLast <= 0 && h.keep.MaxAge <= 0 {
+		return
+	}
+	last := len(h.entries) - 1
+	kept := 0
+	for i, e := range h.entries {
+		switch {
+		case i == last,
+			h.keep.KeepLast > 0 && i >= len(h.entries)-h.keep.KeepLast,
+			h.keep.MaxAge > 0 && now.Sub(e.replaced) < h.keep.MaxAge,
+			h.pinned[e.Gen] > 0:
+			h.entries[kept] = e
+			kept++
+		}
+	}
+	// Clear out the pruned entries so their Trees can be collected.
+	for i := kept; i < len(h.entries); i++ {
+		h.entries[i] = histEntry[T]{}
+	}
+	h.entries = h.entries[:kept]
+}
@@ -0,0 +1,91 @@
+package avl
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeClock returns a clock for History that only moves when the test says so.
+func fakeClock(start time.Time) (now func() time.Time, advance func(time.Duration)) {
+	cur := start
+	return func() time.Time { return cur }, func(d time.Duration) { cur = cur.Add(d) }
+}
+
+func TestHistory(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now, advance := fakeClock(start)
+	h := NewHistory(New[int](il), Retention{})
+	h.now = now
+	h.entries[0].Time = start
+	tree := h.Current().Tree
+	for i := 1; i <= 10; i++ {
+		advance(time.Minute)
+		tree = tree.Insert(i)
+		if gen := h.Commit(tree); gen != uint64(i) {
+			t.Fatalf("Commit: expected gen %d, got %d", i, gen)
+		}
+	}
+	for i := 0; i <= 10; i++ {
+		if v, ok := h.At(uint64(i)); !ok || v.Len() != i {
+			t.Fatalf("At(%d): got %v", i, ok)
+		}
+		v, ok := h.AsOf(start.Add(time.Duration(i)*time.Minute + time.Second))
+		if !ok || v.Gen != uint64(i) {
+			t.Fatalf("AsOf minute %d: got gen %d, %v", i, v.Gen, ok)
+		}
+	}
+	if _, ok := h.AsOf(start.Add(-time.Second)); ok {
+		t.Fatalf("AsOf before the first version found one")
+	}
+	if _, ok := h.At(11); ok {
+		t.Fatalf("At found a version that was never committed")
+	}
+}
+
+func TestHistoryRetention(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now, advance := fakeClock(start)
+	h := NewHistory(New[int](il), Retention{KeepLast: 3, MaxAge: 5 * time.Minute})
+	h.now = now
+	h.entries[0].Time = start
+	if !h.Pin(0) {
+		t.Fatalf("Pin of the first version failed")
+	}
+	tree := h.Current().Tree
+	for i := 1; i <= 20; i++ {
+		advance(time.Minute)
+		tree = tree.Insert(i)
+		h.Commit(tree)
+	}
+	// Gen 0 is pinned, 15 through 20 were current within the last 5 minutes.
+	var gens []uint64
+	for _, v := range h.Versions() {
+		gens = append(gens, v.Gen)
+	}
+	if expect := []uint64{0, 15, 16, 17, 18, 19, 20}; !reflect.DeepEqual(expect, gens) {
+		t.Fatalf("Retained versions: expected %v, got %v", expect, gens)
+	}
+	if _, ok := h.AsOf(start.Add(90 * time.Second)); ok {
+		t.Fatalf("AsOf found a pruned version")
+	}
+	if v, ok := h.AsOf(start.Add(30 * time.Second)); !ok || v.Gen != 0 {
+		t.Fatalf("AsOf did not find the pinned version")
+	}
+	if h.Pin(5) {
+		t.Fatalf("Pin of a pruned version succeeded")
+	}
+	h.Unpin(0)
+	if _, ok := h.At(0); ok {
+		t.Fatalf("Unpin did not prune")
+	}
+	// Once nothing is committed for a while, only KeepLast keeps versions around.
+	advance(time.Hour)
+	h.Prune()
+	if vs := h.Versions(); len(vs) != 3 || vs[0].Gen != 18 {
+		t.Fatalf("Prune: expected the last 3 versions, got %d", len(vs))
+	}
+	if cap(h.entries) > len(h.entries) && h.entries[:cap(h.entries)][len(h.entries)].Tree != nil {
+		t.Fatalf("Prune left a reference to a pruned Tree")
+	}
+}
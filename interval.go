@@ -0,0 +1,133 @@
+package avl
+
+import "iter"
+
+// maxEnd is the summary an IntervalTree keeps in each node: the largest interval end in the subtree.
+type maxEnd[P any] struct {
+	hi P
+	ok bool // False for the summary of an empty subtree.
+}
+
+// IntervalTree is an immutable AVL Tree of items that each cover a closed interval of points of type P.
+// Items are ordered by where their intervals start, and every node keeps track of the largest interval end in its
+// subtree, which lets queries for intervals that contain a point or overlap a range skip subtrees that
+// cannot contain matches.  Like Tree, any operation that would change an IntervalTree returns a new one that
+// shares unaltered nodes with the original.
+type IntervalTree[T, P any] struct {
+	a    *Augmented[T, maxEnd[P]]
+	pl   LessThan[P]
+	span func(T) (lo, hi P)
+}
+
+// NewIntervalTree allocates a new IntervalTree.  span returns the interval an item covers, pl orders points,
+// and lt is used to order items whose intervals start at the same point.
+func NewIntervalTree[T, P any](lt LessThan[T], pl LessThan[P], span func(T) (lo, hi P), items ...T) *IntervalTree[T, P] {
+	less := func(a, b T) bool {
+		alo, _ := span(a)
+		blo, _ := span(b)
+		switch {
+		case pl(alo, blo):
+			return true
+		case pl(blo, alo):
+			return false
+		default:
+			return lt(a, b)
+		}
+	}
+	m := Monoid[T, maxEnd[P]]{
+		Of: func(v T) maxEnd[P] {
+			_, hi := span(v)
+			return maxEnd[P]{hi: hi, ok: true}
+		},
+		Combine: func(a, b maxEnd[P]) maxEnd[P] {
+			if !a.ok || (b.ok && pl(a.hi, b.hi)) {
+				return b
+			}
+			return a
+		},
+	}
+	return &IntervalTree[T, P]{a: NewAugmented[T, maxEnd[P]](less, m, items...), pl: pl, span: span}
+}
+
+// with wraps a in a new IntervalTree that has the same orderings as it.
+func (it *IntervalTree[T, P]) with(a *Augmented[T, maxEnd[P]]) *IntervalTree[T, P] {
+	return &IntervalTree[T, P]{a: a, pl: it.pl, span: it.span}
+}
+
+// Len returns the number of items in the IntervalTree.
+func (it *IntervalTree[T, P]) Len() int { return it.a.Len() }
+
+// Insert returns a new IntervalTree that has the data from it and any passed-in data.
+func (it *IntervalTree[T, P]) Insert(items ...T) *IntervalTree[T, P] {
+	return it.with(it.a.Insert(items...))
+}
+
+// Delete returns a new IntervalTree with the passed-in item removed, along with the removed
+// item and whether an item was removed.
+func (it *IntervalTree[T, P]) Delete(item T) (into *IntervalTree[T, P], deleted T, found bool) {
+	var a *Augmented[T, maxEnd[P]]
+	a, deleted, found = it.a.Delete(item)
+	return it.with(a), deleted, found
+}
+
+// Values returns an iter.Seq that yields every item in the IntervalTree ordered by where their intervals start.
+func (it *IntervalTree[T, P]) Values() iter.Seq[T] {
+	return it.a.Values()
+}
+
+// overlaps returns true if v covers any point in [lo, hi].
+func (it *IntervalTree[T, P]) overlaps(v T, lo, hi P) bool {
+	vlo, vhi := it.span(v)
+	return !it.pl(hi, vlo) && !it.pl(vhi, lo)
+}
+
+// Overlapping returns an iter.Seq that yields every item whose interval overlaps [lo, hi],
+// ordered by where their intervals start.  It runs in O(log n + k) time for k matching items.
+func (it *IntervalTree[T, P]) Overlapping(lo, hi P) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		var visit func(n *node[augItem[T, maxEnd[P]]]) bool
+		visit = func(n *node[augItem[T, maxEnd[P]]]) bool {
+			if n == nil || it.pl(n.i.s.hi, lo) {
+				// Nothing in this subtree ends at or after lo.
+				return true
+			}
+			if !visit(n.c[l]) {
+				return false
+			}
+			if vlo, _ := it.span(n.i.i); it.pl(hi, vlo) {
+				// This interval and everything to the right of it start after hi.
+				return true
+			}
+			if it.overlaps(n.i.i, lo, hi) && !yield(n.i.i) {
+				return false
+			}
+			return visit(n.c[r])
+		}
+		visit(it.a.t.root)
+	}
+}
+
+// Stabbing returns an iter.Seq that yields every item whose interval contains point,
+// ordered by where their intervals start.  It runs in O(log n + k) time for k matching items.
+func (it *IntervalTree[T, P]) Stabbing(point P) iter.Seq[T] {
+	return it.Overlapping(point, point)
+}
+
+// AnyOverlap returns an item whose interval overlaps [lo, hi] and true, or a zero T and false
+// if there is no such item.  It runs in O(log n) time.
+func (it *IntervalTree[T, P]) AnyOverlap(lo, hi P) (item T, found bool) {
+	n := it.a.t.root
+	for n != nil {
+		if it.overlaps(n.i.i, lo, hi) {
+			return n.i.i, true
+		}
+		// If anything on the left ends at or after lo, then either something on the left overlaps,
+		// or nothing does -- everything on the right starts after everything on the left.
+		if n.c[l] != nil && !it.pl(n.c[l].i.s.hi, lo) {
+			n = n.c[l]
+		} else {
+			n = n.c[r]
+		}
+	}
+	return
+}
@@ -0,0 +1,71 @@
+package avl
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+type span struct{ lo, hi int }
+
+func spanLess(a, b span) bool  { return a.hi < b.hi }
+func spanOf(s span) (int, int) { return s.lo, s.hi }
+
+func TestIntervalTree(t *testing.T) {
+	src := rand.New(rand.NewSource(23))
+	it := NewIntervalTree[span, int](spanLess, il, spanOf)
+	var all []span
+	for i := 0; i < 500; i++ {
+		lo := src.Intn(1000)
+		s := span{lo, lo + src.Intn(50)}
+		it = it.Insert(s)
+		all = append(all, s)
+	}
+	model := map[span]bool{}
+	for _, s := range all {
+		model[s] = true
+	}
+	for _, s := range all[:100] {
+		it, _, _ = it.Delete(s)
+		delete(model, s)
+	}
+	if it.Len() != len(model) {
+		t.Fatalf("Len: expected %d, got %d", len(model), it.Len())
+	}
+	checkSummaries(t, it.a)
+	for i := 0; i < 300; i++ {
+		lo := src.Intn(1100) - 50
+		hi := lo + src.Intn(30)
+		var expect, got []span
+		for s := range it.Values() {
+			if s.lo <= hi && s.hi >= lo {
+				expect = append(expect, s)
+			}
+		}
+		for s := range it.Overlapping(lo, hi) {
+			got = append(got, s)
+		}
+		if !reflect.DeepEqual(expect, got) {
+			t.Fatalf("Overlapping(%d, %d): expected %v, got %v", lo, hi, expect, got)
+		}
+		if s, ok := it.AnyOverlap(lo, hi); ok != (len(expect) > 0) || (ok && !(s.lo <= hi && s.hi >= lo)) {
+			t.Fatalf("AnyOverlap(%d, %d): got %v %v", lo, hi, s, ok)
+		}
+		got = nil
+		for s := range it.Stabbing(lo) {
+			if s.lo > lo || s.hi < lo {
+				t.Fatalf("Stabbing(%d) returned %v", lo, s)
+			}
+			got = append(got, s)
+		}
+		n := 0
+		for s := range it.Values() {
+			if s.lo <= lo && s.hi >= lo {
+				n++
+			}
+		}
+		if n != len(got) {
+			t.Fatalf("Stabbing(%d): expected %d items, got %d", lo, n, len(got))
+		}
+	}
+}
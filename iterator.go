@@ -63,6 +63,19 @@ type Iter[T any] interface {
 	Item() T
 }
 
+// Seeker is implemented by Iters that can jump to a new position without restarting
+// iteration.  All of the Iters that Tree returns implement Seeker.
+type Seeker[T any] interface {
+	Iter[T]
+	// Seek moves the Iter to the first item at or after the item cmp wraps in the direction
+	// the Iter is moving in, and returns true if there is such an item.  Seek will only move to
+	// items within the bounds the Iter was created with.  If iteration has not started yet, Seek will
+	// move as if Next had been called.  If Seek returns true, Item will return the item it moved to,
+	// otherwise the Iter is released.  Seek runs in O(log n) time, and can move backwards
+	// as well as forwards.
+	Seek(cmp CompareAgainst[T]) bool
+}
+
 // cmpIter holds state needed to iterate over a binary Tree using specified start
 // and stop conditions.
 type cmpIter[T any] struct {
@@ -199,6 +212,34 @@ func (i *cmpIter[T]) changeDirection() bool {
 	return true
 }
 
+// Seek moves to the first item at or after cmp in the current direction of iteration.
+// When iterating in ascending order, that is the smallest item that is not less than cmp,
+// and when iterating in descending order it is the largest item that is not greater than cmp.
+func (i *cmpIter[T]) Seek(cmp CompareAgainst[T]) bool {
+	if i.t == nil {
+		return false
+	}
+	ascending := i.ascending || len(i.stack) == 0
+	i.clearStack()
+	i.workingNode = i.t.root
+	if ascending {
+		old := i.start
+		i.start = func(v T) bool { return cmp(v) == Less || (old != nil && old(v)) }
+		if !i.init(true, i.stop) {
+			return false
+		}
+		i.start = old
+	} else {
+		old := i.stop
+		i.stop = func(v T) bool { return cmp(v) == Greater || (old != nil && old(v)) }
+		if !i.init(false, i.start) {
+			return false
+		}
+		i.stop = old
+	}
+	return true
+}
+
 // Next walks to the next larger node in the Tree and returns true,
 // or returns false if there is no next larger node to walk to.
 //
@@ -235,7 +276,7 @@ func (i *cmpIter[T]) Next() bool {
 // the current node contains.
 func (i *cmpIter[T]) Prev() bool {
 	if len(i.stack) == 0 {
-		return i.init(false, i.stop)
+		return i.init(false, i.start)
 	}
 	if i.ascending && !i.changeDirection() {
 		return false
@@ -351,6 +392,7 @@ type rangeIter[T any] struct {
 	t             *Tree[T]
 	stack         []*node[T]
 	offset, limit int
+	pos           int // Position of the current item in the Tree.
 }
 
 func (ri *rangeIter[T]) workingNode() *node[T] {
@@ -401,28 +443,44 @@ func (ri *rangeIter[T]) min(n *node[T]) {
 }
 
 func (ri *rangeIter[T]) next() {
-	if ri.offset > 0 {
-		ri.offset--
-	}
 	n := ri.pop()
 	if n != nil && n.c[r] != nil {
 		ri.min(n.c[r])
 	}
 }
 
+// seek fills the stack with the path to the item at position k in the sorted order of the subtree at n,
+// leaving the stack in the same state that walking to that item one step at a time would.
+func (ri *rangeIter[T]) seek(n *node[T], k int) {
+	for n != nil {
+		ls := n.c[l].size()
+		switch {
+		case k < ls:
+			ri.stack = append(ri.stack, n)
+			n = n.c[l]
+		case k > ls:
+			k -= ls + 1
+			n = n.c[r]
+		default:
+			ri.stack = append(ri.stack, n)
+			return
+		}
+	}
+}
+
 func (ri *rangeIter[T]) Next() bool {
 	if len(ri.stack) == 0 {
 		if ri.t == nil {
 			return false
 		}
 		if ri.t.root != nil {
-			ri.min(ri.t.root)
-		}
-		for ri.offset > 0 && len(ri.stack) > 0 {
-			ri.next()
+			// Subtree sizes let us jump straight to offset instead of walking there.
+			ri.seek(ri.t.root, ri.offset)
 		}
+		ri.pos = ri.offset
 	} else {
 		ri.next()
+		ri.pos++
 	}
 	if ri.limit == 0 || ri.workingNode() == nil {
 		ri.Release()
@@ -434,15 +492,61 @@ func (ri *rangeIter[T]) Next() bool {
 	return true
 }
 
+// Seek moves to the first item at or after cmp, as long as it is within the
+// offset and limit the rangeIter was created with.
+func (ri *rangeIter[T]) Seek(cmp CompareAgainst[T]) bool {
+	if ri.t == nil {
+		return false
+	}
+	end := -1
+	if ri.limit >= 0 {
+		if len(ri.stack) == 0 {
+			end = ri.offset + ri.limit
+		} else {
+			end = ri.pos + 1 + ri.limit
+		}
+	}
+	pos := ri.t.Rank(cmp)
+	if pos < ri.offset {
+		pos = ri.offset
+	}
+	if end >= 0 && pos >= end {
+		ri.Release()
+		return false
+	}
+	for k := range ri.stack {
+		ri.stack[k] = nil
+	}
+	ri.stack = ri.stack[:0]
+	ri.seek(ri.t.root, pos)
+	if len(ri.stack) == 0 {
+		ri.Release()
+		return false
+	}
+	ri.pos = pos
+	if end >= 0 {
+		ri.limit = end - pos - 1
+	}
+	return true
+}
+
 // OffsetAndLimit returns an Iter that skips the first offset items
 // and returns up to limit items. Passing limit of -1 will cause
 // OffsetAndLimit to iterate to the last item in the tree.
 //
+// Finding the item at offset takes O(log n) time, regardless of how large offset is.
+//
 // The Iter returned by OffsetAndLimit cannot run backwards -- the
 // Prev() method will always return false and not affect the current
 // position of the Iter.
 func (t *Tree[T]) OffsetAndLimit(offset, limit int) Iter[T] {
-	return &rangeIter[T]{t: t, offset: offset, limit: limit}
+	return t.rangeIter(offset, limit)
+}
+
+// rangeIter makes a rangeIter over t.  A negative offset is treated as 0, so that
+// the position of each item the rangeIter returns is always its rank in the Tree.
+func (t *Tree[T]) rangeIter(offset, limit int) *rangeIter[T] {
+	return &rangeIter[T]{t: t, offset: max(offset, 0), limit: limit}
 }
 
 // All returns an iterator that will walk over the entries in the tree.
@@ -0,0 +1,202 @@
+package avl
+
+// fix recalculates the height and subtree size of n, performing a single or double
+// rotation if n no longer meets the AVL balance criteria.  n must already be owned by ns.
+// fix returns the new root of the subtree.
+func (ns *nodeStack[T]) fix(n *node[T]) *node[T] {
+	var from, to, tooHeavyOn int
+	switch n.balance() {
+	case Less, Equal, Greater:
+		ns.setHeight(n)
+		return n
+	case rightHeavy:
+		from, to, tooHeavyOn = r, l, Less
+	case leftHeavy:
+		from, to, tooHeavyOn = l, r, Greater
+	default:
+		panic("Tree too far out of shape!")
+	}
+	n.c[from] = ns.copy(n.c[from])
+	if n.c[from].balance() == tooHeavyOn {
+		n.c[from].c[to] = ns.copy(n.c[from].c[to])
+		n.c[from] = n.c[from].rotate(from, to)
+		ns.setHeight(n.c[from].c[from])
+		if ns.counting {
+			ns.costs.Rotations++
+		}
+	}
+	if ns.counting {
+		ns.costs.Rotations++
+	}
+	n = n.rotate(to, from)
+	ns.setHeight(n.c[to])
+	ns.setHeight(n)
+	return n
+}
+
+// join returns a subtree containing everything in left, then mid, then right.
+// Every item in left must sort before mid, and every item in right must sort after it.
+// mid must be owned by ns, and its children will be overwritten.
+// join runs in time proportional to the difference in height between left and right.
+func (ns *nodeStack[T]) join(left, mid, right *node[T]) *node[T] {
+	lh, rh := left.height(), right.height()
+	switch {
+	case lh > rh+1:
+		return ns.joinDown(left, mid, right, r)
+	case rh > lh+1:
+		return ns.joinDown(right, mid, left, l)
+	}
+	mid.c[l], mid.c[r] = left, right
+	ns.setHeight(mid)
+	return mid
+}
+
+// joinDown walks down the dir side of tall until it finds a subtree that is no more
+// than one level taller than short, replaces it with mid joining the two, and then
+// rebalances on the way back up.
+func (ns *nodeStack[T]) joinDown(tall, mid, short *node[T], dir int) *node[T] {
+	tall = ns.copy(tall)
+	if c := tall.c[dir]; c.height() <= short.height()+1 {
+		mid.c[flip(dir)], mid.c[dir] = c, short
+		ns.setHeight(mid)
+		tall.c[dir] = mid
+	} else {
+		tall.c[dir] = ns.joinDown(c, mid, short, dir)
+	}
+	return ns.fix(tall)
+}
+
+// joinAt is join for a mid node that may be shared with other trees.
+// If mid already has left and right as children it is returned as-is,
+// otherwise it is copied before joining.
+func (ns *nodeStack[T]) joinAt(mid, left, right *node[T]) *node[T] {
+	if mid.c[l] == left && mid.c[r] == right {
+		return mid
+	}
+	return ns.join(left, ns.copy(mid), right)
+}
+
+// popEnd removes the node furthest in direction dir from the subtree at n.
+// It returns what is left of the subtree along with the removed node, which
+// will be owned by ns.
+func (ns *nodeStack[T]) popEnd(n *node[T], dir int) (rest, end *node[T]) {
+	if n.c[dir] == nil {
+		return n.c[flip(dir)], ns.copy(n)
+	}
+	n = ns.copy(n)
+	n.c[dir], end = ns.popEnd(n.c[dir], dir)
+	return ns.fix(n), end
+}
+
+// concat returns a subtree containing everything in left followed by everything in right.
+// Every item in left must sort before every item in right.
+func (ns *nodeStack[T]) concat(left, right *node[T]) *node[T] {
+	switch {
+	case left == nil:
+		return right
+	case right == nil:
+		return left
+	}
+	var mid *node[T]
+	if left.h() > right.h() {
+		left, mid = ns.popEnd(left, r)
+	} else {
+		right, mid = ns.popEnd(right, l)
+	}
+	return ns.join(left, mid, right)
+}
+
+// split divides the subtree at n into the items that cmp considers Less, the node
+// that cmp considers Equal (if there is one), and the items that cmp considers Greater.
+// The Equal node is returned as-is, and may still be shared with other trees.
+// split runs in O(log n) time.
+func (ns *nodeStack[T]) split(n *node[T], cmp CompareAgainst[T]) (less, equal, greater *node[T]) {
+	if n == nil {
+		return
+	}
+	if ns.counting {
+		ns.costs.Compares++
+	}
+	switch cmp(n.i) {
+	case Equal:
+		return n.c[l], n, n.c[r]
+	case Greater:
+		less, equal, greater = ns.split(n.c[l], cmp)
+		greater = ns.join(greater, ns.copy(n), n.c[r])
+	case Less:
+		less, equal, greater = ns.split(n.c[r], cmp)
+		less = ns.join(n.c[l], ns.copy(n), less)
+	default:
+		panic(unorderable)
+	}
+	return
+}
+
+// Split divides t into a Tree holding the items that cmp considers Less, the item that cmp
+// considers Equal (if there is one), and a Tree holding the items that cmp considers Greater.
+// t is left unchanged, and all three Trees will share nodes with it.
+// Split runs in O(log n) time.
+func (t *Tree[T]) Split(cmp CompareAgainst[T]) (less *Tree[T], equal T, found bool, greater *Tree[T]) {
+	less, greater = t.derive(), t.derive()
+	ins := less.getNs()
+	defer less.putNs(ins)
+	lr, eq, gr := ins.split(t.root, cmp)
+	less.setRoot(lr)
+	greater.setRoot(gr)
+	if eq != nil {
+		equal, found = eq.i, true
+	}
+	return
+}
+
+const unjoinable = `Trees passed to Join are not in order`
+
+// Join returns a new Tree that contains all the items in left followed by all the items in right.
+// Every item in left must be less than every item in right according to left's LessThan, which
+// the returned Tree will also use.  Join will panic if that is not the case.
+// left and right are left unchanged, and the returned Tree will share nodes with both.
+// Join runs in O(log n) time.
+func Join[T any](left, right *Tree[T]) *Tree[T] {
+	compares := 0
+	if lmax, ok := left.Max(); ok {
+		if rmin, ok := right.Min(); ok {
+			compares++
+			if !left.less(lmax, rmin) {
+				panic(unjoinable)
+			}
+		}
+	}
+	res := left.derive(right)
+	ins := res.getNs()
+	defer res.putNs(ins)
+	ins.costs.Compares = compares
+	res.setRoot(ins.concat(left.root, right.root))
+	return res
+}
+
+// Join3 returns a new Tree that contains all the items in left, followed by mid, followed by all
+// the items in right.  Every item in left must be less than mid and mid must be less than every item in
+// right according to left's LessThan, which the returned Tree will also use.  Join3 will panic if that
+// is not the case.  left and right are left unchanged, and the returned Tree will share nodes with both.
+// Join3 runs in O(log n) time.
+func Join3[T any](left *Tree[T], mid T, right *Tree[T]) *Tree[T] {
+	compares := 0
+	if lmax, ok := left.Max(); ok {
+		compares++
+		if !left.less(lmax, mid) {
+			panic(unjoinable)
+		}
+	}
+	if rmin, ok := right.Min(); ok {
+		compares++
+		if !left.less(mid, rmin) {
+			panic(unjoinable)
+		}
+	}
+	res := left.derive(right)
+	ins := res.getNs()
+	defer res.putNs(ins)
+	ins.costs.Compares = compares
+	res.setRoot(ins.join(left.root, ins.newNode(mid), right.root))
+	return res
+}
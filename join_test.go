@@ -0,0 +1,98 @@
+package avl
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func seqTree(from, to int) *Tree[int] {
+	return CreateWith[int](il, func(t func(int)) {
+		for i := from; i < to; i++ {
+			t(i)
+		}
+	})
+}
+
+func seq(from, to int) (res []int) {
+	for i := from; i < to; i++ {
+		res = append(res, i)
+	}
+	return
+}
+
+func TestSplit(t *testing.T) {
+	n := 500
+	src := rand.New(rand.NewSource(11))
+	tree := New[int](il)
+	var expect []int
+	for _, v := range src.Perm(n) {
+		tree = tree.Insert(v * 2)
+	}
+	for i := 0; i < n; i++ {
+		expect = append(expect, i*2)
+	}
+	for i := -1; i <= n*2; i++ {
+		less, eq, found, greater := tree.Split(tree.Cmp(i))
+		less.root.balanced(t)
+		greater.root.balanced(t)
+		if found != (i >= 0 && i < n*2 && i%2 == 0) || (found && eq != i) {
+			t.Fatalf("Split at %d: got %d %v", i, eq, found)
+		}
+		got := treeItems(less)
+		if found {
+			got = append(got, eq)
+		}
+		got = append(got, treeItems(greater)...)
+		if !reflect.DeepEqual(expect, got) {
+			t.Fatalf("Split at %d: expected %v, got %v", i, expect, got)
+		}
+		if v, ok := less.Max(); ok && v >= i {
+			t.Fatalf("Split at %d: %d in less", i, v)
+		}
+		if v, ok := greater.Min(); ok && v <= i {
+			t.Fatalf("Split at %d: %d in greater", i, v)
+		}
+	}
+	if !reflect.DeepEqual(treeItems(tree), expect) {
+		t.Fatalf("Split modified the original Tree")
+	}
+}
+
+func TestJoin(t *testing.T) {
+	for _, sz := range [][2]int{{0, 0}, {0, 5}, {5, 0}, {1, 1}, {1, 1000}, {1000, 1}, {100, 100}, {3, 70}, {700, 30}} {
+		left, right := seqTree(0, sz[0]), seqTree(sz[0]+1, sz[0]+1+sz[1])
+		joined := Join(left, right)
+		joined.root.balanced(t)
+		expect := append(seq(0, sz[0]), seq(sz[0]+1, sz[0]+1+sz[1])...)
+		if got := treeItems(joined); !reflect.DeepEqual(expect, got) || joined.Len() != len(expect) {
+			t.Fatalf("Join %v: expected %v, got %v", sz, expect, got)
+		}
+		joined = Join3(left, sz[0], right)
+		joined.root.balanced(t)
+		expect = seq(0, sz[0]+1+sz[1])
+		if got := treeItems(joined); !reflect.DeepEqual(expect, got) || joined.Len() != len(expect) {
+			t.Fatalf("Join3 %v: expected %v, got %v", sz, expect, got)
+		}
+		if got := treeItems(left); len(got) != sz[0] {
+			t.Fatalf("Join modified the left Tree")
+		}
+	}
+}
+
+func TestJoinPanics(t *testing.T) {
+	for name, fn := range map[string]func(){
+		"Join":        func() { Join(seqTree(0, 10), seqTree(5, 15)) },
+		"Join3 left":  func() { Join3(seqTree(0, 10), 9, seqTree(10, 15)) },
+		"Join3 right": func() { Join3(seqTree(0, 10), 10, seqTree(10, 15)) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("%s did not panic on overlapping Trees", name)
+				}
+			}()
+			fn()
+		}()
+	}
+}
@@ -0,0 +1,187 @@
+package avl
+
+import "iter"
+
+// entry is a key/value pair stored in the Tree that backs a Map.
+// Only the key is used for ordering.
+type entry[K, V any] struct {
+	k K
+	v V
+}
+
+// key returns the key e is stored under, for use with through.
+func (e entry[K, V]) key() K { return e.k }
+
+// Map is an immutable ordered map from K to V.  It is a thin wrapper around a Tree, and shares
+// its copy-on-write behaviour: Set, Delete, and Update return a new Map that shares unaltered
+// nodes with the Map they were called on.  Lookups only need a key.
+type Map[K, V any] struct {
+	t    *Tree[entry[K, V]]
+	less LessThan[K]
+}
+
+// NewMap allocates a new empty Map that keeps its keys ordered according to lt.
+func NewMap[K, V any](lt LessThan[K]) *Map[K, V] {
+	return &Map[K, V]{
+		t:    New[entry[K, V]](func(a, b entry[K, V]) bool { return lt(a.k, b.k) }),
+		less: lt,
+	}
+}
+
+// with wraps t in a new Map that has the same ordering as m.
+func (m *Map[K, V]) with(t *Tree[entry[K, V]]) *Map[K, V] {
+	return &Map[K, V]{t: t, less: m.less}
+}
+
+// cmp makes a CompareAgainst for the backing Tree out of a key.
+func (m *Map[K, V]) cmp(k K) CompareAgainst[entry[K, V]] {
+	return through(cmpFor(m.less, k), entry[K, V].key)
+}
+
+// Less returns the LessThan function that the Map uses to order keys.
+func (m *Map[K, V]) Less() LessThan[K] {
+	return m.less
+}
+
+// Cmp takes a reference key and makes a valid CompareAgainst using the Map's
+// LessThan.  It can be passed to TestMakers to build the Tests that Range expects.
+func (m *Map[K, V]) Cmp(reference K) CompareAgainst[K] {
+	return cmpFor(m.less, reference)
+}
+
+// Len returns the number of keys in the Map.
+func (m *Map[K, V]) Len() int { return m.t.Len() }
+
+// Get returns the value stored for k and true, or a zero V and false if k is not in the Map.
+func (m *Map[K, V]) Get(k K) (v V, found bool) {
+	var e entry[K, V]
+	if e, found = m.t.Get(m.cmp(k)); found {
+		v = e.v
+	}
+	return
+}
+
+// Has returns true if k is in the Map.
+func (m *Map[K, V]) Has(k K) bool {
+	return m.t.Has(m.cmp(k))
+}
+
+// Min returns the smallest key in the Map and its value, or zero values and false if the Map is empty.
+func (m *Map[K, V]) Min() (k K, v V, found bool) {
+	var e entry[K, V]
+	e, found = m.t.Min()
+	return e.k, e.v, found
+}
+
+// Max returns the largest key in the Map and its value, or zero values and false if the Map is empty.
+func (m *Map[K, V]) Max() (k K, v V, found bool) {
+	var e entry[K, V]
+	e, found = m.t.Max()
+	return e.k, e.v, found
+}
+
+// Set returns a new Map that has v stored for k, replacing any value k already had.
+func (m *Map[K, V]) Set(k K, v V) *Map[K, V] {
+	return m.with(m.t.Insert(entry[K, V]{k: k, v: v}))
+}
+
+// SetWith returns a new Map that has every key and value that fill passes in.  Like InsertWith,
+// it amortizes the cost of copy-on-write across all of them.
+func (m *Map[K, V]) SetWith(fill func(func(K, V))) *Map[K, V] {
+	return m.with(m.t.InsertWith(func(f func(entry[K, V])) {
+		fill(func(k K, v V) { f(entry[K, V]{k: k, v: v}) })
+	}))
+}
+
+// Delete returns a new Map without k, along with the value k had and whether
+// k was in the Map.  The original Map is left unchanged.
+func (m *Map[K, V]) Delete(k K) (into *Map[K, V], v V, found bool) {
+	var t *Tree[entry[K, V]]
+	var e entry[K, V]
+	t, e, found = m.t.Delete(entry[K, V]{k: k})
+	return m.with(t), e.v, found
+}
+
+// Update calls fn with the current value of k and whether k is in the Map.  If fn returns true,
+// the returned Map will have the value fn returned stored for k, otherwise k will be removed.
+func (m *Map[K, V]) Update(k K, fn func(v V, found bool) (V, bool)) *Map[K, V] {
+	old, found := m.Get(k)
+	v, keep := fn(old, found)
+	switch {
+	case keep:
+		return m.Set(k, v)
+	case found:
+		res, _, _ := m.Delete(k)
+		return res
+	default:
+		return m
+	}
+}
+
+// All returns an iter.Seq2 that yields every key and value in the Map in ascending key order.
+func (m *Map[K, V]) All() iter.Seq2[K, V] {
+	return m.Range(nil, nil)
+}
+
+// Backward returns an iter.Seq2 that yields every key and value in the Map in descending key order.
+func (m *Map[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for e := range m.t.Backward() {
+			if !yield(e.k, e.v) {
+				return
+			}
+		}
+	}
+}
+
+// Keys returns an iter.Seq that yields every key in the Map in ascending order.
+func (m *Map[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for e := range m.t.Values() {
+			if !yield(e.k) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns an iter.Seq that yields every value in the Map in ascending key order.
+func (m *Map[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for e := range m.t.Values() {
+			if !yield(e.v) {
+				return
+			}
+		}
+	}
+}
+
+// Range returns an iter.Seq2 that yields the keys and values in the Map in ascending key order,
+// ignoring all keys to the left that start returns true for and all keys to the right that stop
+// returns true for.  If either start or stop is nil, then that condition will not apply.
+//
+// Lt  start == inclusive, Lte start == exclusive
+// Gte stop  == exclusive, Gt  stop  == inclusive
+func (m *Map[K, V]) Range(start, stop Test[K]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for e := range m.t.Between(through(start, entry[K, V].key), through(stop, entry[K, V].key)) {
+			if !yield(e.k, e.v) {
+				return
+			}
+		}
+	}
+}
+
+// CountRange returns the number of keys that Range would yield for the same start and stop Tests
+// in O(log n) time.
+func (m *Map[K, V]) CountRange(start, stop Test[K]) int {
+	return m.t.CountRange(through(start, entry[K, V].key), through(stop, entry[K, V].key))
+}
+
+// DeleteRange returns a new Map without any of the keys that Range would yield for the same
+// start and stop Tests, along with the number of keys removed.
+func (m *Map[K, V]) DeleteRange(start, stop Test[K]) (into *Map[K, V], deleted int) {
+	var t *Tree[entry[K, V]]
+	t, deleted = m.t.DeleteRange(through(start, entry[K, V].key), through(stop, entry[K, V].key))
+	return m.with(t), deleted
+}
@@ -0,0 +1,113 @@
+package avl
+
+import (
+	"math/rand"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestMap(t *testing.T) {
+	src := rand.New(rand.NewSource(17))
+	m := NewMap[int, string](il)
+	model := map[int]string{}
+	for i := 0; i < 2000; i++ {
+		k := src.Intn(500)
+		switch src.Intn(3) {
+		case 0, 1:
+			v := strconv.Itoa(i)
+			m = m.Set(k, v)
+			model[k] = v
+		case 2:
+			var v string
+			var found bool
+			m, v, found = m.Delete(k)
+			mv, mfound := model[k]
+			if found != mfound || v != mv {
+				t.Fatalf("Delete(%d): got %q %v, expected %q %v", k, v, found, mv, mfound)
+			}
+			delete(model, k)
+		}
+	}
+	if m.Len() != len(model) {
+		t.Fatalf("Len: got %d, expected %d", m.Len(), len(model))
+	}
+	for k := 0; k < 500; k++ {
+		v, found := m.Get(k)
+		mv, mfound := model[k]
+		if found != mfound || v != mv || m.Has(k) != mfound {
+			t.Fatalf("Get(%d): got %q %v, expected %q %v", k, v, found, mv, mfound)
+		}
+	}
+	prev := -1
+	n := 0
+	for k, v := range m.All() {
+		if k <= prev || model[k] != v {
+			t.Fatalf("All: got %d:%q after %d", k, v, prev)
+		}
+		prev = k
+		n++
+	}
+	if n != len(model) {
+		t.Fatalf("All: got %d items, expected %d", n, len(model))
+	}
+}
+
+func TestMapUpdate(t *testing.T) {
+	m := NewMap[string, int](sl)
+	incr := func(v int, _ bool) (int, bool) { return v + 1, true }
+	m = m.Update("a", incr).Update("a", incr).Update("b", incr)
+	if v, _ := m.Get("a"); v != 2 {
+		t.Fatalf("Update: expected a=2, got %d", v)
+	}
+	m2 := m.Update("a", func(int, bool) (int, bool) { return 0, false })
+	if m2.Has("a") || !m.Has("a") || m2.Len() != 1 {
+		t.Fatalf("Update did not delete a from only the new Map")
+	}
+	if m3 := m2.Update("z", func(int, bool) (int, bool) { return 0, false }); m3 != m2 {
+		t.Fatalf("Update that changed nothing returned a new Map")
+	}
+}
+
+func TestMapRange(t *testing.T) {
+	m := NewMap[int, int](il).SetWith(func(set func(int, int)) {
+		for i := 0; i < 100; i++ {
+			set(i, i*i)
+		}
+	})
+	var keys, vals []int
+	for k, v := range m.Range(Lt(m.Cmp(10)), Gte(m.Cmp(15))) {
+		keys = append(keys, k)
+		vals = append(vals, v)
+	}
+	if !reflect.DeepEqual(keys, []int{10, 11, 12, 13, 14}) || !reflect.DeepEqual(vals, []int{100, 121, 144, 169, 196}) {
+		t.Fatalf("Range: got %v %v", keys, vals)
+	}
+	if n := m.CountRange(Lt(m.Cmp(10)), Gte(m.Cmp(15))); n != 5 {
+		t.Fatalf("CountRange: got %d", n)
+	}
+	m2, deleted := m.DeleteRange(nil, Gte(m.Cmp(90)))
+	if deleted != 90 || m2.Len() != 10 {
+		t.Fatalf("DeleteRange: deleted %d, %d left", deleted, m2.Len())
+	}
+	if k, v, ok := m2.Min(); !ok || k != 90 || v != 8100 {
+		t.Fatalf("Min after DeleteRange: got %d %d", k, v)
+	}
+	keys = nil
+	for k := range m2.Keys() {
+		keys = append(keys, k)
+	}
+	if !reflect.DeepEqual(keys, seq(90, 100)) {
+		t.Fatalf("Keys: got %v", keys)
+	}
+	keys = nil
+	for k := range m2.Backward() {
+		keys = append(keys, k)
+		if len(keys) == 2 {
+			break
+		}
+	}
+	if !reflect.DeepEqual(keys, []int{99, 98}) {
+		t.Fatalf("Backward: got %v", keys)
+	}
+}
@@ -0,0 +1,169 @@
+package avl
+
+import "iter"
+
+// dup is an item stored in the Tree that backs a Multiset.  seq records the order items
+// were inserted in, and is used to keep equal items apart.
+type dup[T any] struct {
+	i   T
+	seq uint64
+}
+
+// item returns the item d holds, for use with through.
+func (d dup[T]) item() T { return d.i }
+
+// Multiset is an immutable AVL Tree that can hold several items that are equal to each other.
+// Equal items are kept in the order they were inserted in.  Like Tree, any operation that would
+// change a Multiset returns a new one that shares unaltered nodes with the original.
+type Multiset[T any] struct {
+	t    *Tree[dup[T]]
+	less LessThan[T]
+	seq  uint64 // Sequence number to give to the next inserted item.
+}
+
+// NewMultiset allocates a new Multiset that will keep itself ordered according to lt.
+func NewMultiset[T any](lt LessThan[T], items ...T) *Multiset[T] {
+	res := &Multiset[T]{
+		t: New[dup[T]](func(a, b dup[T]) bool {
+			switch {
+			case lt(a.i, b.i):
+				return true
+			case lt(b.i, a.i):
+				return false
+			default:
+				return a.seq < b.seq
+			}
+		}),
+		less: lt,
+	}
+	return res.Insert(items...)
+}
+
+// with wraps t in a new Multiset that has the same ordering as m.
+func (m *Multiset[T]) with(t *Tree[dup[T]], seq uint64) *Multiset[T] {
+	return &Multiset[T]{t: t, less: m.less, seq: seq}
+}
+
+// Less returns the LessThan function that the Multiset is using.
+func (m *Multiset[T]) Less() LessThan[T] {
+	return m.less
+}
+
+// Cmp takes a reference T and makes a valid CompareAgainst using the Multiset's LessThan.
+func (m *Multiset[T]) Cmp(reference T) CompareAgainst[T] {
+	return cmpFor(m.less, reference)
+}
+
+// Len returns the number of items in the Multiset, counting every duplicate.
+func (m *Multiset[T]) Len() int { return m.t.Len() }
+
+// Insert returns a new Multiset that has the data from m and all the passed-in items.
+// Items that are equal to ones already in m are added after them.
+func (m *Multiset[T]) Insert(items ...T) *Multiset[T] {
+	seq := m.seq
+	return m.with(m.t.InsertWith(func(f func(dup[T])) {
+		for i := range items {
+			f(dup[T]{i: items[i], seq: seq})
+			seq++
+		}
+	}), seq)
+}
+
+// Get returns the highest item in the Multiset that is equal to CompareAgainst and true,
+// or a zero T and false if there is no such item.  Of several equal items, the one
+// inserted last is the highest.
+func (m *Multiset[T]) Get(cmp CompareAgainst[T]) (item T, found bool) {
+	pos := m.t.prefixLen(through(Lte(cmp), dup[T].item)) - 1
+	if d, ok := m.t.Select(pos); ok && cmp(d.i) == Equal {
+		item, found = d.i, true
+	}
+	return
+}
+
+// Has returns true if the Multiset contains at least one item equal to CompareAgainst.
+func (m *Multiset[T]) Has(cmp CompareAgainst[T]) bool {
+	return m.t.Has(through(cmp, dup[T].item))
+}
+
+// Count returns the number of items in the Multiset that are equal to CompareAgainst in O(log n) time.
+func (m *Multiset[T]) Count(cmp CompareAgainst[T]) int {
+	return m.t.CountRange(through(Lt(cmp), dup[T].item), through(Gt(cmp), dup[T].item))
+}
+
+// EqualRange returns an iter.Seq that yields every item in the Multiset that is equal to
+// CompareAgainst, in the order they were inserted.
+func (m *Multiset[T]) EqualRange(cmp CompareAgainst[T]) iter.Seq[T] {
+	return m.Between(Lt(cmp), Gt(cmp))
+}
+
+// DeleteOne returns a new Multiset with the earliest inserted item equal to item removed,
+// along with the removed item and whether an item was removed.
+// The original Multiset is left unchanged.
+func (m *Multiset[T]) DeleteOne(item T) (into *Multiset[T], deleted T, found bool) {
+	cmp := m.Cmp(item)
+	d, ok := m.t.Select(m.t.Rank(through(cmp, dup[T].item)))
+	if !ok || cmp(d.i) != Equal {
+		return m, deleted, false
+	}
+	t, _, _ := m.t.Delete(d)
+	return m.with(t, m.seq), d.i, true
+}
+
+// DeleteAll returns a new Multiset without any of the items equal to CompareAgainst, along
+// with the number of items removed.  The original Multiset is left unchanged.
+func (m *Multiset[T]) DeleteAll(cmp CompareAgainst[T]) (into *Multiset[T], deleted int) {
+	var t *Tree[dup[T]]
+	t, deleted = m.t.DeleteRange(through(Lt(cmp), dup[T].item), through(Gt(cmp), dup[T].item))
+	return m.with(t, m.seq), deleted
+}
+
+// Range will iterate through the Multiset in ascending order, visiting every duplicate,
+// ignoring all items to the left that start returns true for
+// and all items in the right that end returns true for.
+// Iteration will also stop if iterator returns false.
+//
+// Lt  start == inclusive, Lte start == exclusive
+// Gte stop  == exclusive, Gt  stop  == inclusive
+func (m *Multiset[T]) Range(start, stop, iterator Test[T]) {
+	m.t.Range(through(start, dup[T].item), through(stop, dup[T].item), func(d dup[T]) bool { return iterator(d.i) })
+}
+
+// After will iterate through the Multiset in ascending order, visiting every duplicate,
+// ignoring items on the left that start returns true for.
+// Iteration will also stop when iterator returns false.
+//
+// Lt start == inclusive, Lte start = exclusive
+func (m *Multiset[T]) After(start, iterator Test[T]) {
+	m.Range(start, nil, iterator)
+}
+
+// Before will iterate through the Multiset in ascending order, visiting every duplicate,
+// ignoring items on the right that end returns true for.
+// Iteration will stop if iterator returns false.
+//
+// Gt stop == inclusive, Gte stop = exclusive
+func (m *Multiset[T]) Before(stop, iterator Test[T]) {
+	m.Range(nil, stop, iterator)
+}
+
+// Walk will call iterator once for each item in the Multiset in ascending order.
+// Walk will return early if iterator returns false.
+func (m *Multiset[T]) Walk(iterator Test[T]) {
+	m.Range(nil, nil, iterator)
+}
+
+// Values returns an iter.Seq that yields every item in the Multiset in ascending order.
+func (m *Multiset[T]) Values() iter.Seq[T] {
+	return m.Between(nil, nil)
+}
+
+// Between returns an iter.Seq that yields the same items as Range would for the same start and stop Tests.
+func (m *Multiset[T]) Between(start, stop Test[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for d := range m.t.Between(through(start, dup[T].item), through(stop, dup[T].item)) {
+			if !yield(d.i) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package avl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMultiset(t *testing.T) {
+	ms := NewMultiset[ovr](ol, ovr{1, 1}, ovr{2, 1}, ovr{2, 2}, ovr{3, 1})
+	ms = ms.Insert(ovr{2, 3}, ovr{0, 1}, ovr{3, 2})
+	if ms.Len() != 7 {
+		t.Fatalf("Len: expected 7, got %d", ms.Len())
+	}
+	two := ms.Cmp(ovr{i: 2})
+	if n := ms.Count(two); n != 3 {
+		t.Fatalf("Count: expected 3, got %d", n)
+	}
+	var res []ovr
+	for v := range ms.EqualRange(two) {
+		res = append(res, v)
+	}
+	if expect := []ovr{{2, 1}, {2, 2}, {2, 3}}; !reflect.DeepEqual(expect, res) {
+		t.Fatalf("EqualRange: expected %v, got %v", expect, res)
+	}
+	if v, ok := ms.Get(two); !ok || v.mark != 3 {
+		t.Fatalf("Get: expected the last inserted item, got %v", v)
+	}
+	if _, ok := ms.Get(ms.Cmp(ovr{i: 5})); ok {
+		t.Fatalf("Get found an item that is not there")
+	}
+	res = nil
+	ms.Range(Lt(ms.Cmp(ovr{i: 1})), Gt(ms.Cmp(ovr{i: 2})), func(v ovr) bool {
+		res = append(res, v)
+		return true
+	})
+	if expect := []ovr{{1, 1}, {2, 1}, {2, 2}, {2, 3}}; !reflect.DeepEqual(expect, res) {
+		t.Fatalf("Range: expected %v, got %v", expect, res)
+	}
+	res = nil
+	ms.After(Lte(two), func(v ovr) bool {
+		res = append(res, v)
+		return true
+	})
+	if expect := []ovr{{3, 1}, {3, 2}}; !reflect.DeepEqual(expect, res) {
+		t.Fatalf("After: expected %v, got %v", expect, res)
+	}
+	res = nil
+	ms.Before(Gt(ms.Cmp(ovr{i: 0})), func(v ovr) bool {
+		res = append(res, v)
+		return true
+	})
+	if expect := []ovr{{0, 1}}; !reflect.DeepEqual(expect, res) {
+		t.Fatalf("Before: expected %v, got %v", expect, res)
+	}
+	ms2, deleted, found := ms.DeleteOne(ovr{i: 2})
+	if !found || deleted.mark != 1 || ms2.Count(two) != 2 || ms.Count(two) != 3 {
+		t.Fatalf("DeleteOne removed %v", deleted)
+	}
+	if _, _, found = ms2.DeleteOne(ovr{i: 7}); found {
+		t.Fatalf("DeleteOne removed an item that is not there")
+	}
+	ms3, n := ms2.DeleteAll(two)
+	if n != 2 || ms3.Has(two) || ms3.Len() != 4 {
+		t.Fatalf("DeleteAll removed %d items", n)
+	}
+	ms3 = ms3.Insert(ovr{2, 4})
+	res = nil
+	for v := range ms3.Values() {
+		res = append(res, v)
+	}
+	if expect := []ovr{{0, 1}, {1, 1}, {2, 4}, {3, 1}, {3, 2}}; !reflect.DeepEqual(expect, res) {
+		t.Fatalf("Values: expected %v, got %v", expect, res)
+	}
+}
@@ -25,7 +25,8 @@ type node[T any] struct {
 	// event you encounter this scenario, that insert or delete operation will make a new copy of the
 	// whole tree instead of only copying what is needed for that particular operation.
 	genH uint64
-	i    T // The item the node is holding.
+	sz   int // The number of nodes in the subtree rooted at this node, including this one.
+	i    T   // The item the node is holding.
 }
 
 const (
@@ -51,6 +52,14 @@ func (n *node[T]) h() uint64 {
 	return n.genH & hMask
 }
 
+// height returns the node's height, or 0 if there is no node.
+func (n *node[T]) height() uint64 {
+	if n == nil {
+		return 0
+	}
+	return n.h()
+}
+
 func (n *node[T]) balance() (res int) {
 	if n.c[l] != nil {
 		res -= int(n.c[l].h())
@@ -99,9 +108,24 @@ func (n *node[T]) setH(h uint64) {
 	n.genH |= h
 }
 
-// setHeight calculates the height of this node.
+// setHeight calculates the height and subtree size of this node.
 func (n *node[T]) setHeight() {
 	n.setH(n.maxChildHeight() + 1)
+	n.setSize()
+}
+
+// size returns the number of nodes in the subtree rooted at n.
+// It is safe to call on a nil node.
+func (n *node[T]) size() int {
+	if n == nil {
+		return 0
+	}
+	return n.sz
+}
+
+// setSize recalculates the subtree size of this node from its children.
+func (n *node[T]) setSize() {
+	n.sz = n.c[l].size() + n.c[r].size() + 1
 }
 
 /*
@@ -117,6 +141,10 @@ func (n *node[T]) rotate(from, to int) (m *node[T]) {
 	m = n.c[to]
 	n.c[to] = m.c[from]
 	m.c[from] = n
+	// Subtree sizes only depend on the children, so they can be fixed up here.
+	// Heights are left to the caller, which knows which ones actually changed.
+	n.setSize()
+	m.setSize()
 	return
 }
 
@@ -127,13 +155,20 @@ func (t *Tree[T]) getExact(ins *nodeStack[T], v T) (res int) {
 	ins.add(t.root)
 	var dir int
 	for n := t.root; n != nil; {
+		if ins.counting {
+			ins.costs.Compares++
+		}
 		if t.less(n.i, v) {
 			dir, res = r, Greater
-		} else if t.less(v, n.i) {
-			dir, res = l, Less
 		} else {
-			res = Equal
-			break
+			if ins.counting {
+				ins.costs.Compares++
+			}
+			if !t.less(v, n.i) {
+				res = Equal
+				break
+			}
+			dir, res = l, Less
 		}
 		if n.c[dir] == nil {
 			break
@@ -4,8 +4,11 @@ package avl
 // The node at position 0 is the root of the tree, and the node at position len(n.s)-1 is
 // always the current working node of the subset of the tree we are working with.
 type nodeStack[T any] struct {
-	s   []*node[T] // The stack of nodes we are currently manipulating.
-	gen uint64     // The generation of the tree we are operating on.
+	s        []*node[T]     // The stack of nodes we are currently manipulating.
+	gen      uint64         // The generation of the tree we are operating on.
+	aug      func(*node[T]) // Recalculates augmented data for a node, if the tree we are operating on keeps any.
+	counting bool           // Whether to keep costs up to date.  Only set for trees made by WithCosts.
+	costs    OpCost         // The work done so far by the operation using the nodeStack.
 }
 
 // Clear the nodeStack for reuse in a new operation.
@@ -15,7 +18,30 @@ func (ns *nodeStack[T]) clear() {
 
 // Add a new node[T] to the nodeStack.  All nodes are added at the leaf, so get height 1
 func (ns *nodeStack[T]) newNode(v T) *node[T] {
-	return &node[T]{i: v, genH: (ns.gen << hOffset) | 0x01}
+	if ns.counting {
+		ns.costs.Allocated++
+	}
+	n := &node[T]{i: v, genH: (ns.gen << hOffset) | 0x01, sz: 1}
+	if ns.aug != nil {
+		ns.aug(n)
+	}
+	return n
+}
+
+// update recalculates the subtree size of n along with any augmented data the tree keeps.
+func (ns *nodeStack[T]) update(n *node[T]) {
+	n.setSize()
+	if ns.aug != nil {
+		ns.aug(n)
+	}
+}
+
+// setHeight recalculates the height, subtree size, and any augmented data of n.
+func (ns *nodeStack[T]) setHeight(n *node[T]) {
+	n.setHeight()
+	if ns.aug != nil {
+		ns.aug(n)
+	}
 }
 
 // copy makes a copy of the passed-in node if it is of a different gen than the tree.
@@ -23,7 +49,10 @@ func (ns *nodeStack[T]) copy(n *node[T]) *node[T] {
 	if n.gen() == ns.gen {
 		return n
 	}
-	return &node[T]{c: n.c, i: n.i, genH: (ns.gen << hOffset) | (n.h())}
+	if ns.counting {
+		ns.costs.Copied++
+	}
+	return &node[T]{c: n.c, i: n.i, sz: n.sz, genH: (ns.gen << hOffset) | (n.h())}
 }
 
 // Add the node to the nodeStack.
@@ -72,10 +101,19 @@ func (ns *nodeStack[T]) drop() {
 	ns.s = ns.s[:ns.pos(-1)]
 }
 
+// resize recalculates subtree sizes and augmented data starting at absolute position i
+// in the nodeStack and walking up to the root.
+func (ns *nodeStack[T]) resize(i int) {
+	for ; i >= 0; i-- {
+		ns.update(ns.s[i])
+	}
+}
+
 // rebalance walks up the Tree starting at node n, rebalancing nodes
 // that no longer meet the AVL balance criteria. rebalance will continue until
 // it either walks all the way up the Tree, or the node has the
-// same height it started with.
+// same height it started with.  Once rebalancing is finished, the subtree
+// sizes and augmented data of the remaining nodes up to the root are updated.
 func (ns *nodeStack[T]) rebalance() {
 	var n *node[T]
 	for i := len(ns.s) - 1; i >= 0; i-- {
@@ -86,8 +124,10 @@ func (ns *nodeStack[T]) rebalance() {
 		case Less, Equal, Greater:
 			// The tree is not too far out of the AVL balance criteria. We don't need to do anything beyond
 			// exiting early if the tree height does not change.
+			ns.update(n)
 			if childH+1 == n.h() {
 				// If the node height did not change, we are done.
+				ns.resize(i - 1)
 				return
 			} else {
 				n.setH(childH + 1)
@@ -107,18 +147,25 @@ func (ns *nodeStack[T]) rebalance() {
 			// AVL balanced at the end of this rebalance operation.
 			n.c[from].c[to] = ns.copy(n.c[from].c[to])
 			n.c[from] = n.c[from].rotate(from, to)
-			n.c[from].c[from].setHeight()
+			ns.setHeight(n.c[from].c[from])
+			if ns.counting {
+				ns.costs.Rotations++
+			}
+		}
+		if ns.counting {
+			ns.costs.Rotations++
 		}
 		if i > 0 {
 			n = ns.s[i-1].swapChild(n, n.rotate(to, from))
 		} else {
 			n = n.rotate(to, from)
 		}
-		n.c[to].setHeight()
+		ns.setHeight(n.c[to])
 		ns.s[i] = n
-		n.setHeight()
+		ns.setHeight(n)
 		if childH+1 == n.h() {
 			// If the node height did not change, we are done.
+			ns.resize(i - 1)
 			return
 		}
 	}
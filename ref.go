@@ -0,0 +1,159 @@
+package avl
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// refState is a Tree published by a Ref, along with its version.  It is never modified after
+// it is published, except to note that a newer refState has replaced it.
+type refState[T any] struct {
+	t       *Tree[T]
+	version uint64
+	mu      sync.Mutex
+	done    bool          // Set once a newer refState has been published.
+	changed chan struct{} // Created on demand by WaitForChange, and closed once done is set.
+}
+
+// replaced marks s as replaced by a newer refState, waking up anything waiting on it.
+func (s *refState[T]) replaced() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done = true
+	if s.changed != nil {
+		close(s.changed)
+	}
+}
+
+// wait returns a channel that will be closed once s has been replaced.
+func (s *refState[T]) wait() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.changed == nil {
+		s.changed = make(chan struct{})
+		if s.done {
+			close(s.changed)
+		}
+	}
+	return s.changed
+}
+
+// RefStats holds counters that track how much contention a Ref has seen.
+type RefStats struct {
+	Commits   uint64 // Number of times a new Tree was published.
+	Retries   uint64 // Number of times Update had to call its function again because another update won.
+	CASFailed uint64 // Number of times CompareAndSwap failed because old was no longer current.
+}
+
+// Ref holds the current version of a Tree that is shared between goroutines.  Readers call Load to get
+// the current Tree, which they can use for as long as they like without locking since Trees are immutable.
+// Writers publish new versions of the Tree with Swap, CompareAndSwap, or Update.
+//
+// Every Tree published to a Ref gets a version number that is one larger than the one it replaced,
+// starting from 0 for the Tree passed to NewRef.
+type Ref[T any] struct {
+	p                        atomic.Pointer[refState[T]]
+	commits, retries, failed atomic.Uint64
+	wmu                      sync.Mutex // Serializes sending Events to watchers.
+	watchers                 map[*Watcher[T]]struct{}
+	nwatch                   atomic.Int32
+}
+
+// NewRef creates a new Ref that holds t.
+func NewRef[T any](t *Tree[T]) *Ref[T] {
+	res := &Ref[T]{}
+	res.p.Store(&refState[T]{t: t})
+	return res
+}
+
+// publish tries to replace cur with t, returning the new version and whether it succeeded.
+func (r *Ref[T]) publish(cur *refState[T], t *Tree[T]) (uint64, bool) {
+	next := &refState[T]{t: t, version: cur.version + 1}
+	if !r.p.CompareAndSwap(cur, next) {
+		return 0, false
+	}
+	r.commits.Add(1)
+	cur.replaced()
+	r.notify()
+	return next.version, true
+}
+
+// Load returns the current Tree.
+func (r *Ref[T]) Load() *Tree[T] {
+	return r.p.Load().t
+}
+
+// LoadVersion returns the current Tree and its version.
+func (r *Ref[T]) LoadVersion() (*Tree[T], uint64) {
+	s := r.p.Load()
+	return s.t, s.version
+}
+
+// Version returns the version of the current Tree.
+func (r *Ref[T]) Version() uint64 {
+	return r.p.Load().version
+}
+
+// Swap publishes t unconditionally, returning the Tree it replaced.
+func (r *Ref[T]) Swap(t *Tree[T]) (old *Tree[T]) {
+	for {
+		cur := r.p.Load()
+		if _, ok := r.publish(cur, t); ok {
+			return cur.t
+		}
+	}
+}
+
+// CompareAndSwap publishes new if old is the current Tree, and returns whether it did.
+func (r *Ref[T]) CompareAndSwap(old, new *Tree[T]) bool {
+	for {
+		cur := r.p.Load()
+		if cur.t != old {
+			r.failed.Add(1)
+			return false
+		}
+		if _, ok := r.publish(cur, new); ok {
+			return true
+		}
+	}
+}
+
+// Update calls fn with the current Tree and publishes the Tree it returns.  If another goroutine publishes
+// a new Tree in the meantime, Update calls fn again with that Tree, until it succeeds.  fn may be called
+// several times, so it should not have side effects.  If fn returns the Tree it was passed, nothing is published.
+// Update returns the Tree it published.
+func (r *Ref[T]) Update(fn func(*Tree[T]) *Tree[T]) *Tree[T] {
+	for {
+		cur := r.p.Load()
+		next := fn(cur.t)
+		if next == cur.t {
+			return next
+		}
+		if _, ok := r.publish(cur, next); ok {
+			return next
+		}
+		r.retries.Add(1)
+	}
+}
+
+// Stats returns the contention counters for r.
+func (r *Ref[T]) Stats() RefStats {
+	return RefStats{Commits: r.commits.Load(), Retries: r.retries.Load(), CASFailed: r.failed.Load()}
+}
+
+// WaitForChange blocks until a Tree with a version newer than version is published, and then returns
+// the current Tree and its version.  If ctx is done first, WaitForChange returns ctx.Err().
+func (r *Ref[T]) WaitForChange(ctx context.Context, version uint64) (*Tree[T], uint64, error) {
+	for {
+		cur := r.p.Load()
+		if cur.version > version {
+			return cur.t, cur.version, nil
+		}
+		select {
+		case <-cur.wait():
+		case <-ctx.Done():
+			return nil, 0, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package avl
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRef(t *testing.T) {
+	base := New[int](il)
+	ref := NewRef(base)
+	if ref.Load() != base || ref.Version() != 0 {
+		t.Fatalf("NewRef did not hold its Tree")
+	}
+	next := base.Insert(1)
+	if old := ref.Swap(next); old != base || ref.Version() != 1 {
+		t.Fatalf("Swap: got %v, version %d", old, ref.Version())
+	}
+	if ref.CompareAndSwap(base, base.Insert(2)) {
+		t.Fatalf("CompareAndSwap succeeded against a stale Tree")
+	}
+	if !ref.CompareAndSwap(next, next.Insert(2)) || ref.Load().Len() != 2 {
+		t.Fatalf("CompareAndSwap failed against the current Tree")
+	}
+	cur := ref.Load()
+	if ref.Update(func(t *Tree[int]) *Tree[int] { return t }) != cur || ref.Version() != 2 {
+		t.Fatalf("Update that changed nothing published a new version")
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				ref.Update(func(t *Tree[int]) *Tree[int] { return t.Insert(100 + i*1000 + j) })
+			}
+		}(i)
+	}
+	wg.Wait()
+	if ref.Load().Len() != 2+8*200 {
+		t.Fatalf("Update lost writes: expected %d items, got %d", 2+8*200, ref.Load().Len())
+	}
+	stats := ref.Stats()
+	if stats.Commits != 2+8*200 || stats.CASFailed != 1 || ref.Version() != stats.Commits {
+		t.Fatalf("Stats: got %+v at version %d", stats, ref.Version())
+	}
+}
+
+func TestRefWaitForChange(t *testing.T) {
+	ref := NewRef(New[int](il))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, _, err := ref.WaitForChange(ctx, 0); err != context.DeadlineExceeded {
+		t.Fatalf("WaitForChange without a change: expected a timeout, got %v", err)
+	}
+	done := make(chan uint64)
+	go func() {
+		_, v, _ := ref.WaitForChange(context.Background(), 0)
+		done <- v
+	}()
+	time.Sleep(5 * time.Millisecond)
+	ref.Update(func(t *Tree[int]) *Tree[int] { return t.Insert(1) })
+	select {
+	case v := <-done:
+		if v != 1 {
+			t.Fatalf("WaitForChange: expected version 1, got %d", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("WaitForChange did not wake up")
+	}
+	if tree, v, err := ref.WaitForChange(context.Background(), 0); err != nil || v != 1 || tree.Len() != 1 {
+		t.Fatalf("WaitForChange for an old version did not return at once")
+	}
+}
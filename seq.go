@@ -0,0 +1,133 @@
+package avl
+
+import "iter"
+
+// Seq adapts an Iter into an iter.Seq that can be used in a range loop.
+// The Iter is consumed by the loop, and is released when the loop finishes
+// or breaks out early, so the returned iter.Seq can only be used once.
+func Seq[T any](i Iter[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		defer i.Release()
+		for i.Next() {
+			if !yield(i.Item()) {
+				return
+			}
+		}
+	}
+}
+
+// pullIter adapts an iter.Seq into an Iter.
+type pullIter[T any] struct {
+	next func() (T, bool)
+	stop func()
+	item T
+	ok   bool
+}
+
+// Release stops the underlying iter.Seq.
+func (p *pullIter[T]) Release() {
+	if p.stop != nil {
+		p.stop()
+	}
+	p.next, p.stop, p.ok = nil, nil, false
+	var zero T
+	p.item = zero
+}
+
+// Next fetches the next item from the underlying iter.Seq.
+func (p *pullIter[T]) Next() bool {
+	if p.next == nil {
+		return false
+	}
+	if p.item, p.ok = p.next(); !p.ok {
+		p.Release()
+	}
+	return p.ok
+}
+
+// Prev is not defined for a pullIter.
+func (p *pullIter[T]) Prev() bool {
+	return false
+}
+
+func (p *pullIter[T]) Item() T {
+	if !p.ok {
+		panic("No iteration in progress")
+	}
+	return p.item
+}
+
+// FromSeq adapts an iter.Seq into an Iter, which can then be passed to InsertFrom or DeleteFrom.
+// The returned Iter cannot run backwards -- the Prev() method will always return false.
+// Call Release if you stop calling Next before it returns false to free the resources
+// held by the underlying iter.Seq.
+func FromSeq[T any](seq iter.Seq[T]) Iter[T] {
+	next, stop := iter.Pull(seq)
+	return &pullIter[T]{next: next, stop: stop}
+}
+
+// Values returns an iter.Seq that yields every item in the Tree in ascending order.
+//
+//	for v := range tree.Values() {
+//	    fmt.Println(v)
+//	}
+func (t *Tree[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		Seq(t.All())(yield)
+	}
+}
+
+// Backward returns an iter.Seq that yields every item in the Tree in descending order.
+func (t *Tree[T]) Backward() iter.Seq[T] {
+	return t.BetweenBackward(nil, nil)
+}
+
+// Between returns an iter.Seq that yields the same items in the same order as
+// Range would when passed the same start and stop Tests.
+//
+// Lt  start == inclusive, Lte start == exclusive
+// Gte stop  == exclusive, Gt  stop  == inclusive
+func (t *Tree[T]) Between(start, stop Test[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		i := t.Iterator(start, stop)
+		defer i.Release()
+		for i.Next() {
+			if !yield(i.Item()) {
+				return
+			}
+		}
+	}
+}
+
+// BetweenBackward is Between in descending order.
+func (t *Tree[T]) BetweenBackward(start, stop Test[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		i := t.Iterator(start, stop)
+		defer i.Release()
+		for i.Prev() {
+			if !yield(i.Item()) {
+				return
+			}
+		}
+	}
+}
+
+// Indexed returns an iter.Seq2 that yields every item in the Tree in ascending
+// order along with its position in the Tree.
+func (t *Tree[T]) Indexed() iter.Seq2[int, T] {
+	return t.Window(0, -1)
+}
+
+// Window returns an iter.Seq2 that yields the same items as OffsetAndLimit along with
+// the position of each item in the Tree.
+func (t *Tree[T]) Window(offset, limit int) iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		i := t.rangeIter(offset, limit)
+		defer i.Release()
+		for i.Next() {
+			if !yield(i.pos, i.Item()) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package avl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSeqs(t *testing.T) {
+	tree := seqTree(0, 20)
+	var res []int
+	for v := range tree.Values() {
+		res = append(res, v)
+	}
+	if !reflect.DeepEqual(res, seq(0, 20)) {
+		t.Fatalf("Values: got %v", res)
+	}
+	res = nil
+	for v := range tree.Backward() {
+		if v < 15 {
+			break
+		}
+		res = append(res, v)
+	}
+	if !reflect.DeepEqual(res, []int{19, 18, 17, 16, 15}) {
+		t.Fatalf("Backward: got %v", res)
+	}
+	res = nil
+	for v := range tree.Between(Lt(tree.Cmp(5)), Gte(tree.Cmp(9))) {
+		res = append(res, v)
+	}
+	if !reflect.DeepEqual(res, []int{5, 6, 7, 8}) {
+		t.Fatalf("Between: got %v", res)
+	}
+	res = nil
+	for v := range tree.BetweenBackward(Lt(tree.Cmp(5)), Gte(tree.Cmp(9))) {
+		res = append(res, v)
+	}
+	if !reflect.DeepEqual(res, []int{8, 7, 6, 5}) {
+		t.Fatalf("BetweenBackward: got %v", res)
+	}
+	for range tree.BetweenBackward(Lt(tree.Cmp(9)), Gt(tree.Cmp(5))) {
+		t.Fatalf("BetweenBackward over an empty range yielded an item")
+	}
+	for i, v := range tree.Window(3, 4) {
+		if i != v || i < 3 || i >= 7 {
+			t.Fatalf("Window: got %d at %d", v, i)
+		}
+	}
+	res = res[:0]
+	for i, v := range tree.Window(-3, 2) {
+		if i != v {
+			t.Fatalf("Window with a negative offset: got %d at %d", v, i)
+		}
+		res = append(res, v)
+	}
+	if !reflect.DeepEqual(res, []int{0, 1}) {
+		t.Fatalf("Window with a negative offset: got %v", res)
+	}
+	n := 0
+	for i, v := range tree.Indexed() {
+		if i != v {
+			t.Fatalf("Indexed: got %d at %d", v, i)
+		}
+		n++
+	}
+	if n != 20 {
+		t.Fatalf("Indexed: got %d items", n)
+	}
+}
+
+func TestSeqAdapters(t *testing.T) {
+	tree := seqTree(0, 20)
+	res := treeItems(New[int](il).InsertFrom(FromSeq(tree.Values())))
+	if !reflect.DeepEqual(res, seq(0, 20)) {
+		t.Fatalf("FromSeq: got %v", res)
+	}
+	res = nil
+	for v := range Seq(tree.OffsetAndLimit(5, 3)) {
+		res = append(res, v)
+	}
+	if !reflect.DeepEqual(res, []int{5, 6, 7}) {
+		t.Fatalf("Seq: got %v", res)
+	}
+	stopped := false
+	i := FromSeq(func(yield func(int) bool) {
+		defer func() { stopped = true }()
+		for v := 0; yield(v); v++ {
+		}
+	})
+	if !i.Next() || !i.Next() || i.Item() != 1 {
+		t.Fatalf("FromSeq did not iterate correctly")
+	}
+	i.Release()
+	if !stopped || i.Next() {
+		t.Fatalf("Release did not stop the iter.Seq")
+	}
+}
@@ -0,0 +1,107 @@
+package avl
+
+// The set operations in this file are built on split and join instead of inserting or
+// deleting one item at a time.  That lets them skip over subtrees that both Trees share
+// and reuse nodes from either input Tree in the result, which makes them especially cheap
+// when the Trees are Forked versions of each other.
+//
+// All of them require both Trees to be ordered by the same LessThan.
+
+// Union returns a new Tree containing every item that is in either t or other.
+// Items in other replace equal items in t, the same as t.InsertFrom(other.All()) would.
+// Neither t nor other is modified, and the returned Tree will share nodes with both.
+func (t *Tree[T]) Union(other *Tree[T]) *Tree[T] {
+	res := t.derive(other)
+	ins := res.getNs()
+	defer res.putNs(ins)
+	res.setRoot(res.union(ins, t.root, other.root))
+	return res
+}
+
+func (t *Tree[T]) union(ins *nodeStack[T], a, b *node[T]) *node[T] {
+	switch {
+	case a == b, b == nil:
+		return a
+	case a == nil:
+		return b
+	}
+	less, _, greater := ins.split(a, t.Cmp(b.i))
+	return ins.joinAt(b, t.union(ins, less, b.c[l]), t.union(ins, greater, b.c[r]))
+}
+
+// Intersect returns a new Tree containing the items in t that have an equal item in other.
+// Neither t nor other is modified, and the returned Tree will share nodes with t.
+func (t *Tree[T]) Intersect(other *Tree[T]) *Tree[T] {
+	res := t.derive(other)
+	ins := res.getNs()
+	defer res.putNs(ins)
+	res.setRoot(res.intersect(ins, t.root, other.root))
+	return res
+}
+
+func (t *Tree[T]) intersect(ins *nodeStack[T], a, b *node[T]) *node[T] {
+	switch {
+	case a == b:
+		return a
+	case a == nil, b == nil:
+		return nil
+	}
+	less, equal, greater := ins.split(b, t.Cmp(a.i))
+	left, right := t.intersect(ins, a.c[l], less), t.intersect(ins, a.c[r], greater)
+	if equal == nil {
+		return ins.concat(left, right)
+	}
+	return ins.joinAt(a, left, right)
+}
+
+// Difference returns a new Tree containing the items in t that do not have an equal item in other.
+// Neither t nor other is modified, and the returned Tree will share nodes with t.
+func (t *Tree[T]) Difference(other *Tree[T]) *Tree[T] {
+	res := t.derive(other)
+	ins := res.getNs()
+	defer res.putNs(ins)
+	res.setRoot(res.difference(ins, t.root, other.root))
+	return res
+}
+
+func (t *Tree[T]) difference(ins *nodeStack[T], a, b *node[T]) *node[T] {
+	switch {
+	case a == b, a == nil:
+		return nil
+	case b == nil:
+		return a
+	}
+	less, equal, greater := ins.split(b, t.Cmp(a.i))
+	left, right := t.difference(ins, a.c[l], less), t.difference(ins, a.c[r], greater)
+	if equal != nil {
+		return ins.concat(left, right)
+	}
+	return ins.joinAt(a, left, right)
+}
+
+// SymmetricDifference returns a new Tree containing the items that are in exactly one of t or other.
+// Neither t nor other is modified, and the returned Tree will share nodes with both.
+func (t *Tree[T]) SymmetricDifference(other *Tree[T]) *Tree[T] {
+	res := t.derive(other)
+	ins := res.getNs()
+	defer res.putNs(ins)
+	res.setRoot(res.symmetricDifference(ins, t.root, other.root))
+	return res
+}
+
+func (t *Tree[T]) symmetricDifference(ins *nodeStack[T], a, b *node[T]) *node[T] {
+	switch {
+	case a == b:
+		return nil
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	}
+	less, equal, greater := ins.split(b, t.Cmp(a.i))
+	left, right := t.symmetricDifference(ins, a.c[l], less), t.symmetricDifference(ins, a.c[r], greater)
+	if equal != nil {
+		return ins.concat(left, right)
+	}
+	return ins.joinAt(a, left, right)
+}
@@ -0,0 +1,128 @@
+package avl
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func treeItems[T any](tree *Tree[T]) (res []T) {
+	for iter := tree.All(); iter.Next(); {
+		res = append(res, iter.Item())
+	}
+	return
+}
+
+func randomSet(src *rand.Rand, n, max int) (*Tree[int], map[int]bool) {
+	m := map[int]bool{}
+	tree := New[int](il)
+	for i := 0; i < n; i++ {
+		v := src.Intn(max)
+		m[v] = true
+		tree = tree.Insert(v)
+	}
+	return tree, m
+}
+
+func expectSet(t *testing.T, op string, tree *Tree[int], max int, want func(int) bool) {
+	t.Helper()
+	tree.root.balanced(t)
+	var expect []int
+	for i := 0; i < max; i++ {
+		if want(i) {
+			expect = append(expect, i)
+		}
+	}
+	got := treeItems(tree)
+	if !reflect.DeepEqual(expect, got) {
+		t.Fatalf("%s: expected %v, got %v", op, expect, got)
+	}
+	if tree.Len() != len(expect) {
+		t.Fatalf("%s: expected Len %d, got %d", op, len(expect), tree.Len())
+	}
+}
+
+func TestSetOps(t *testing.T) {
+	src := rand.New(rand.NewSource(3))
+	for _, sz := range [][2]int{{0, 0}, {0, 10}, {10, 0}, {1, 100}, {100, 1}, {50, 50}, {300, 20}, {20, 300}, {500, 500}} {
+		max := 2*(sz[0]+sz[1]) + 1
+		a, am := randomSet(src, sz[0], max)
+		b, bm := randomSet(src, sz[1], max)
+		expectSet(t, "Union", a.Union(b), max, func(i int) bool { return am[i] || bm[i] })
+		expectSet(t, "Intersect", a.Intersect(b), max, func(i int) bool { return am[i] && bm[i] })
+		expectSet(t, "Difference", a.Difference(b), max, func(i int) bool { return am[i] && !bm[i] })
+		expectSet(t, "SymmetricDifference", a.SymmetricDifference(b), max, func(i int) bool { return am[i] != bm[i] })
+		// Make sure the inputs were not modified.
+		expectSet(t, "Original", a, max, func(i int) bool { return am[i] })
+		expectSet(t, "Original", b, max, func(i int) bool { return bm[i] })
+	}
+}
+
+func TestSetOpsPrecedence(t *testing.T) {
+	a := New[ovr](ol, ovr{i: 1, mark: 1}, ovr{i: 2, mark: 1})
+	b := New[ovr](ol, ovr{i: 2, mark: 2}, ovr{i: 3, mark: 2})
+	expect := []ovr{{1, 1}, {2, 2}, {3, 2}}
+	if got := treeItems(a.Union(b)); !reflect.DeepEqual(expect, got) {
+		t.Fatalf("Union: expected %v, got %v", expect, got)
+	}
+	expect = []ovr{{2, 1}}
+	if got := treeItems(a.Intersect(b)); !reflect.DeepEqual(expect, got) {
+		t.Fatalf("Intersect: expected %v, got %v", expect, got)
+	}
+}
+
+func TestSetOpsSharing(t *testing.T) {
+	src := rand.New(rand.NewSource(5))
+	base := New[int](il, src.Perm(10000)...)
+	forked := base.Insert(20000, 20001)
+	forked, _ = forked.DeleteItems(5, 6)
+	if res := base.Union(base); res.root != base.root {
+		t.Fatalf("Union with itself did not reuse the root")
+	}
+	if res := base.Intersect(base); res.root != base.root {
+		t.Fatalf("Intersect with itself did not reuse the root")
+	}
+	if res := base.Difference(base); res.Len() != 0 {
+		t.Fatalf("Difference with itself was not empty")
+	}
+	expectSet(t, "Union", base.Union(forked), 20002, func(i int) bool { return i < 10000 || i >= 20000 })
+	expectSet(t, "Difference", forked.Difference(base), 20002, func(i int) bool { return i >= 20000 })
+	expectSet(t, "SymmetricDifference", base.SymmetricDifference(forked), 20002, func(i int) bool {
+		return i == 5 || i == 6 || i >= 20000
+	})
+	// The union of a Tree and one of its Forks should share most of its nodes with both.
+	res := forked.Union(base)
+	shared := map[*node[int]]bool{}
+	var mark func(n *node[int])
+	mark = func(n *node[int]) {
+		if n != nil {
+			shared[n] = true
+			mark(n.c[l])
+			mark(n.c[r])
+		}
+	}
+	mark(base.root)
+	mark(forked.root)
+	var fresh int
+	var count func(n *node[int])
+	count = func(n *node[int]) {
+		if n != nil {
+			if !shared[n] {
+				fresh++
+			}
+			count(n.c[l])
+			count(n.c[r])
+		}
+	}
+	count(res.root)
+	if fresh > 200 {
+		t.Fatalf("Union allocated %d new nodes out of %d", fresh, res.Len())
+	}
+	// The result must not be able to modify nodes borrowed from either input.
+	mod := res.Insert(-1)
+	expectSet(t, "Original", base, 20002, func(i int) bool { return i < 10000 })
+	expectSet(t, "Original", res, 20002, func(i int) bool { return i < 10000 || i >= 20000 })
+	if _, ok := mod.Fetch(-1); !ok || mod.Len() != res.Len()+1 {
+		t.Fatalf("Failed to insert into union")
+	}
+}
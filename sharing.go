@@ -0,0 +1,127 @@
+package avl
+
+import "math/bits"
+
+const tooManyTrees = `SharingReport can only compare up to 64 Trees`
+
+// SharedNodes returns the number of nodes that a and b have in common, along with the number of
+// nodes that only a and only b use.  a and b must be ordered by the same LessThan.
+//
+// SharedNodes walks a and b together in order the same way Diff does, and skips any subtree that
+// both walks reach at the same point.  For Trees that were Forked from each other, that keeps the
+// walk close to the number of nodes they do not share, no matter how large they are.
+func SharedNodes[T any](a, b *Tree[T]) (shared, onlyA, onlyB int) {
+	var sa, sb diffSide[T]
+	sa.push(a.root, true)
+	sb.push(b.root, true)
+	less := a.less
+	for len(sa) > 0 && len(sb) > 0 {
+		fa, fb := sa.top(), sb.top()
+		switch {
+		case fa.whole && fb.whole && fa.n == fb.n:
+			shared += fa.n.sz
+			sa.pop()
+			sb.pop()
+			continue
+		case fa.whole && (!fb.whole || fa.n.h() >= fb.n.h()):
+			sa.expand()
+			continue
+		case fb.whole:
+			sb.expand()
+			continue
+		}
+		switch {
+		case less(fa.n.i, fb.n.i):
+			sa.pop()
+		case less(fb.n.i, fa.n.i):
+			sb.pop()
+		default:
+			if fa.n == fb.n {
+				shared++
+			}
+			sa.pop()
+			sb.pop()
+		}
+	}
+	return shared, a.count - shared, b.count - shared
+}
+
+// Sharing describes how a set of Trees share nodes.  It is returned by SharingReport.
+type Sharing struct {
+	Total  int   // Number of distinct nodes used by all the Trees.
+	Unique []int // Unique[i] is the number of nodes that only the i'th Tree uses.
+	// Sets holds the number of nodes used by each combination of Trees.  The key has bit i
+	// set if the i'th Tree uses the nodes, so Sets[1<<i] == Unique[i].
+	Sets map[uint64]int
+}
+
+// Shared returns the number of nodes that both the i'th and the j'th Tree use.
+func (s Sharing) Shared(i, j int) (res int) {
+	want := uint64(1)<<i | uint64(1)<<j
+	for set, count := range s.Sets {
+		if set&want == want {
+			res += count
+		}
+	}
+	return
+}
+
+// SharingReport reports how many nodes each Tree uses on its own, and how many are shared by each
+// combination of Trees.  The nodes a Tree uses only on its own are the ones that would be freed if it
+// were dropped.  It can compare up to 64 Trees at a time.
+//
+// Every distinct node is walked over once, and the walk of each Tree stops at subtrees that an earlier
+// Tree already walked over.  Those subtrees are then marked as being shared with that Tree afterwards,
+// which only descends into the parts of them that were not already known to be shared.
+func SharingReport[T any](trees ...*Tree[T]) Sharing {
+	if len(trees) > 64 {
+		panic(tooManyTrees)
+	}
+	// direct holds the trees that reach each node on their own, or that reach it first
+	// as the root of a subtree that an earlier tree already walked over.
+	direct := map[*node[T]]uint64{}
+	var walk func(*node[T], uint64)
+	walk = func(n *node[T], bit uint64) {
+		if n == nil {
+			return
+		}
+		if mask, ok := direct[n]; ok {
+			direct[n] = mask | bit
+			return
+		}
+		direct[n] = bit
+		walk(n.c[l], bit)
+		walk(n.c[r], bit)
+	}
+	for i, t := range trees {
+		walk(t.root, uint64(1)<<i)
+	}
+	// Push the trees that reach each node down to its children.  A node's mask only ever
+	// gains bits, so no node is visited more than once per tree.
+	masks := make(map[*node[T]]uint64, len(direct))
+	var push func(*node[T], uint64)
+	push = func(n *node[T], inherited uint64) {
+		if n == nil {
+			return
+		}
+		old, ok := masks[n]
+		mask := old | direct[n] | inherited
+		if ok && mask == old {
+			return
+		}
+		masks[n] = mask
+		push(n.c[l], mask)
+		push(n.c[r], mask)
+	}
+	for _, t := range trees {
+		push(t.root, 0)
+	}
+	res := Sharing{Total: len(masks), Unique: make([]int, len(trees)), Sets: map[uint64]int{}}
+	for _, mask := range masks {
+		res.Sets[mask]++
+		if bits.OnesCount64(mask) == 1 {
+			res.Unique[bits.TrailingZeros64(mask)]++
+		}
+	}
+	return res
+}
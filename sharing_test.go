@@ -0,0 +1,98 @@
+package avl
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// nodeSet returns every node in t.
+func nodeSet(t *Tree[int]) map[*node[int]]bool {
+	res := map[*node[int]]bool{}
+	var walk func(*node[int])
+	walk = func(n *node[int]) {
+		if n != nil {
+			res[n] = true
+			walk(n.c[l])
+			walk(n.c[r])
+		}
+	}
+	walk(t.root)
+	return res
+}
+
+func TestSharing(t *testing.T) {
+	src := rand.New(rand.NewSource(43))
+	versions := []*Tree[int]{New[int](il, src.Perm(2000)...)}
+	for i := 0; i < 6; i++ {
+		base := versions[src.Intn(len(versions))]
+		next := base.Insert(2000 + i)
+		next, _ = next.DeleteItems(src.Intn(2000), src.Intn(2000))
+		versions = append(versions, next)
+	}
+	versions = append(versions, New[int](il, seq(0, 10)...), versions[3])
+	sets := make([]map[*node[int]]bool, len(versions))
+	for i := range versions {
+		sets[i] = nodeSet(versions[i])
+	}
+	expect := Sharing{Unique: make([]int, len(versions)), Sets: map[uint64]int{}}
+	masks := map[*node[int]]uint64{}
+	for i, set := range sets {
+		for n := range set {
+			masks[n] |= 1 << i
+		}
+	}
+	for _, mask := range masks {
+		expect.Sets[mask]++
+		for i := range versions {
+			if mask == 1<<i {
+				expect.Unique[i]++
+			}
+		}
+	}
+	expect.Total = len(masks)
+	got := SharingReport(versions...)
+	if !reflect.DeepEqual(expect, got) {
+		t.Fatalf("SharingReport: expected %+v, got %+v", expect, got)
+	}
+	for i := range versions {
+		for j := range versions {
+			n := 0
+			for k := range sets[i] {
+				if sets[j][k] {
+					n++
+				}
+			}
+			if i != j && got.Shared(i, j) != n {
+				t.Fatalf("Shared(%d, %d): expected %d, got %d", i, j, n, got.Shared(i, j))
+			}
+			shared, onlyA, onlyB := SharedNodes(versions[i], versions[j])
+			if shared != n || onlyA != len(sets[i])-n || onlyB != len(sets[j])-n {
+				t.Fatalf("SharedNodes(%d, %d): expected %d shared, got %d, %d, %d", i, j, n, shared, onlyA, onlyB)
+			}
+		}
+	}
+}
+
+func TestSharedNodesSkipsShared(t *testing.T) {
+	for _, size := range []int{1 << 10, 1 << 16} {
+		calls := 0
+		base := New[int](func(a, b int) bool {
+			calls++
+			return a < b
+		}, seq(0, size)...)
+		next := base.Insert(size)
+		next, _, _ = next.Delete(size / 3)
+		calls = 0
+		shared, onlyA, onlyB := SharedNodes(base, next)
+		if shared+onlyA != size || shared+onlyB != size || onlyA > 2*int(base.root.h()) {
+			t.Fatalf("SharedNodes of %d items: got %d, %d, %d", size, shared, onlyA, onlyB)
+		}
+		if calls > 200 {
+			t.Fatalf("SharedNodes of %d items compared %d items, shared subtrees were not skipped", size, calls)
+		}
+		if allocs := testing.AllocsPerRun(10, func() { SharedNodes(base, next) }); allocs > 20 {
+			t.Fatalf("SharedNodes of %d items made %v allocations", size, allocs)
+		}
+	}
+}
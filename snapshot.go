@@ -0,0 +1,192 @@
+package avl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// Snapshot format:
+//
+//	magic    "AVLS"
+//	version  uvarint, currently 1
+//	nodes    uvarint count, followed by that many nodes in post-order.  Each node is
+//	         the left and right child as uvarint indexes into the nodes written so far
+//	         plus one (0 means no child), then a uvarint length and that many bytes of item.
+//	trees    uvarint count, followed by that many root nodes as uvarint indexes plus one.
+//	checksum big-endian CRC-32 (IEEE) of everything before it.
+//
+// Since children are always written before their parents, a node that several trees share is
+// written once, and every index in a well-formed snapshot refers to a node that has already been read.
+const (
+	snapshotMagic   = "AVLS"
+	snapshotVersion = 1
+)
+
+var (
+	// ErrCorruptSnapshot is returned by ReadSnapshot when the snapshot is truncated or damaged.
+	ErrCorruptSnapshot = errors.New("corrupt snapshot")
+	// ErrSnapshotVersion is returned by ReadSnapshot when the snapshot was written in a format it does not know.
+	ErrSnapshotVersion = errors.New("unsupported snapshot version")
+)
+
+// Codec converts items to and from bytes for WriteSnapshot and ReadSnapshot.
+type Codec[T any] struct {
+	Marshal   func(T) ([]byte, error)
+	Unmarshal func([]byte) (T, error)
+}
+
+// WriteSnapshot writes trees to w as a single graph of nodes, using codec to encode the items.
+// Nodes that are shared between the trees (such as the ones Fork and the Insert and Delete
+// functions leave untouched) are only written once, so a snapshot of many versions of a Tree
+// takes roughly as much space as the nodes that differ between them.
+func WriteSnapshot[T any](w io.Writer, codec Codec[T], trees ...*Tree[T]) error {
+	crc := crc32.NewIEEE()
+	bw := bufio.NewWriter(io.MultiWriter(w, crc))
+	var scratch []byte
+	putUvarint := func(v uint64) {
+		scratch = binary.AppendUvarint(scratch[:0], v)
+		bw.Write(scratch)
+	}
+	index := map[*node[T]]uint64{nil: 0}
+	var order []*node[T]
+	var walk func(*node[T])
+	walk = func(n *node[T]) {
+		if _, ok := index[n]; ok {
+			return
+		}
+		walk(n.c[l])
+		walk(n.c[r])
+		order = append(order, n)
+		index[n] = uint64(len(order))
+	}
+	for _, t := range trees {
+		walk(t.root)
+	}
+	bw.WriteString(snapshotMagic)
+	putUvarint(snapshotVersion)
+	putUvarint(uint64(len(order)))
+	for _, n := range order {
+		item, err := codec.Marshal(n.i)
+		if err != nil {
+			return err
+		}
+		putUvarint(index[n.c[l]])
+		putUvarint(index[n.c[r]])
+		putUvarint(uint64(len(item)))
+		bw.Write(item)
+	}
+	putUvarint(uint64(len(trees)))
+	for _, t := range trees {
+		putUvarint(index[t.root])
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	_, err := w.Write(crc.Sum(nil))
+	return err
+}
+
+// ReadSnapshot reads the trees written by WriteSnapshot from src, using codec to decode the items
+// and lt to order the new Trees.  The returned Trees share nodes the same way the Trees that
+// were written did, and are in the same order they were passed to WriteSnapshot.
+//
+// The whole snapshot is checked before any Trees are returned.  If it is truncated, damaged, or its
+// items are not in order according to lt, ReadSnapshot returns an error wrapping ErrCorruptSnapshot.
+func ReadSnapshot[T any](src io.Reader, lt LessThan[T], codec Codec[T]) ([]*Tree[T], error) {
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < len(snapshotMagic)+crc32.Size || string(data[:len(snapshotMagic)]) != snapshotMagic {
+		return nil, fmt.Errorf("%w: not a snapshot", ErrCorruptSnapshot)
+	}
+	body, sum := data[:len(data)-crc32.Size], data[len(data)-crc32.Size:]
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(sum) {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrCorruptSnapshot)
+	}
+	br := bytes.NewReader(body[len(snapshotMagic):])
+	corrupt := func(format string, args ...any) error {
+		return fmt.Errorf("%w: %s", ErrCorruptSnapshot, fmt.Sprintf(format, args...))
+	}
+	if v, err := binary.ReadUvarint(br); err != nil {
+		return nil, corrupt("missing version")
+	} else if v != snapshotVersion {
+		return nil, fmt.Errorf("%w %d", ErrSnapshotVersion, v)
+	}
+	count, err := binary.ReadUvarint(br)
+	// Every node takes at least 3 bytes, which keeps a bad count from making us allocate too much.
+	if err != nil || count > uint64(br.Len())/3 {
+		return nil, corrupt("bad node count")
+	}
+	res := New[T](lt)
+	ins := res.getNs()
+	defer res.putNs(ins)
+	// nodes[0] stands in for a nil child.  lo and hi track the smallest and largest
+	// items in each subtree, which is all we need to check that the whole graph is in order.
+	nodes := make([]*node[T], 1, count+1)
+	lo, hi := make([]*node[T], 1, count+1), make([]*node[T], 1, count+1)
+	for i := uint64(1); i <= count; i++ {
+		var c [2]uint64
+		for d := range c {
+			if c[d], err = binary.ReadUvarint(br); err != nil || c[d] >= i {
+				return nil, corrupt("node %d has a bad child index", i)
+			}
+		}
+		size, err := binary.ReadUvarint(br)
+		if err != nil || size > uint64(br.Len()) {
+			return nil, corrupt("node %d has a bad item length", i)
+		}
+		buf := make([]byte, size)
+		br.Read(buf)
+		item, err := codec.Unmarshal(buf)
+		if err != nil {
+			return nil, fmt.Errorf("%w: node %d: %w", ErrCorruptSnapshot, i, err)
+		}
+		n := ins.newNode(item)
+		n.c[l], n.c[r] = nodes[c[l]], nodes[c[r]]
+		if bal := int(n.c[r].height()) - int(n.c[l].height()); bal < -1 || bal > 1 {
+			return nil, corrupt("node %d is not balanced", i)
+		}
+		ins.setHeight(n)
+		nodeLo, nodeHi := n, n
+		if c[l] != 0 {
+			if !lt(hi[c[l]].i, item) {
+				return nil, corrupt("node %d is out of order", i)
+			}
+			nodeLo = lo[c[l]]
+		}
+		if c[r] != 0 {
+			if !lt(item, lo[c[r]].i) {
+				return nil, corrupt("node %d is out of order", i)
+			}
+			nodeHi = hi[c[r]]
+		}
+		nodes, lo, hi = append(nodes, n), append(lo, nodeLo), append(hi, nodeHi)
+	}
+	treeCount, err := binary.ReadUvarint(br)
+	if err != nil || treeCount > uint64(br.Len()) {
+		return nil, corrupt("bad tree count")
+	}
+	trees := make([]*Tree[T], treeCount)
+	for i := range trees {
+		root, err := binary.ReadUvarint(br)
+		if err != nil || root > count {
+			return nil, corrupt("tree %d has a bad root index", i)
+		}
+		if i == 0 {
+			trees[i] = res
+		} else {
+			trees[i] = New[T](lt)
+		}
+		trees[i].setRoot(nodes[root])
+	}
+	if br.Len() != 0 {
+		return nil, corrupt("trailing data")
+	}
+	return trees, nil
+}
@@ -0,0 +1,88 @@
+package avl
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+var intCodec = Codec[int]{
+	Marshal:   func(v int) ([]byte, error) { return strconv.AppendInt(nil, int64(v), 10), nil },
+	Unmarshal: func(b []byte) (int, error) { return strconv.Atoi(string(b)) },
+}
+
+// countNodes returns the number of distinct nodes in trees.
+func countNodes(trees ...*Tree[int]) int {
+	seen := map[*node[int]]bool{}
+	var walk func(*node[int])
+	walk = func(n *node[int]) {
+		if n == nil || seen[n] {
+			return
+		}
+		seen[n] = true
+		walk(n.c[l])
+		walk(n.c[r])
+	}
+	for _, t := range trees {
+		walk(t.root)
+	}
+	return len(seen)
+}
+
+func TestSnapshot(t *testing.T) {
+	src := rand.New(rand.NewSource(37))
+	versions := []*Tree[int]{New[int](il, seq(0, 2000)...)}
+	for i := 0; i < 30; i++ {
+		next := versions[len(versions)-1].Insert(src.Intn(3000))
+		next, _, _ = next.Delete(src.Intn(3000))
+		versions = append(versions, next)
+	}
+	versions = append(versions, New[int](il))
+	var buf, single bytes.Buffer
+	if err := WriteSnapshot(&buf, intCodec, versions...); err != nil {
+		t.Fatalf("WriteSnapshot: %v", err)
+	}
+	WriteSnapshot(&single, intCodec, versions[0])
+	if buf.Len() > 2*single.Len() {
+		t.Fatalf("Snapshot of %d versions takes %d bytes, one version takes %d", len(versions), buf.Len(), single.Len())
+	}
+	data := buf.Bytes()
+	res, err := ReadSnapshot[int](bytes.NewReader(data), il, intCodec)
+	if err != nil {
+		t.Fatalf("ReadSnapshot: %v", err)
+	}
+	if len(res) != len(versions) {
+		t.Fatalf("ReadSnapshot: expected %d Trees, got %d", len(versions), len(res))
+	}
+	for i := range res {
+		res[i].root.balanced(t)
+		if res[i].Len() != versions[i].Len() || !reflect.DeepEqual(treeItems(versions[i]), treeItems(res[i])) {
+			t.Fatalf("Tree %d did not survive the snapshot", i)
+		}
+	}
+	if expect, got := countNodes(versions...), countNodes(res...); expect != got {
+		t.Fatalf("Sharing not preserved: expected %d nodes, got %d", expect, got)
+	}
+	// Modifying a decoded Tree must not affect the others it shares nodes with.
+	changed := res[5].Insert(-1)
+	changed, _, _ = changed.Delete(1000)
+	if !reflect.DeepEqual(treeItems(versions[5]), treeItems(res[5])) || !reflect.DeepEqual(treeItems(versions[6]), treeItems(res[6])) {
+		t.Fatalf("Modifying a decoded Tree changed the Trees it shares nodes with")
+	}
+	for _, n := range []int{0, 3, len(data) / 2, len(data) - 1} {
+		if _, err = ReadSnapshot[int](bytes.NewReader(data[:n]), il, intCodec); !errors.Is(err, ErrCorruptSnapshot) {
+			t.Fatalf("ReadSnapshot of %d bytes: expected ErrCorruptSnapshot, got %v", n, err)
+		}
+	}
+	bad := append([]byte{}, data...)
+	bad[len(bad)/2] ^= 0x10
+	if _, err = ReadSnapshot[int](bytes.NewReader(bad), il, intCodec); !errors.Is(err, ErrCorruptSnapshot) {
+		t.Fatalf("ReadSnapshot of damaged data: expected ErrCorruptSnapshot, got %v", err)
+	}
+	if _, err = ReadSnapshot[int](bytes.NewReader(data), func(a, b int) bool { return a > b }, intCodec); !errors.Is(err, ErrCorruptSnapshot) {
+		t.Fatalf("ReadSnapshot with the wrong ordering: expected ErrCorruptSnapshot, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package avl
+
+import (
+	"math"
+	"unsafe"
+)
+
+// Stats describes the shape of a Tree and how much memory it uses.  Stats are gathered by Tree.Stats.
+type Stats struct {
+	Count       int            // Number of nodes in the Tree.
+	Height      int            // Length of the longest path from the root to a leaf, counting both ends.  0 for an empty Tree.
+	DepthAvg    float64        // Average depth of the nodes in the Tree, with the root at depth 0.
+	DepthStdDev float64        // Standard deviation of the depths of the nodes in the Tree.
+	Balance     map[int]int    // Number of nodes with each balance factor (right child height - left child height).
+	Generations map[uint64]int // Number of nodes from each generation.
+	NodeBytes   int            // Estimated memory used by the nodes, including the items stored inline in them.
+	ItemBytes   int            // Estimated memory used by the items outside of the nodes, as reported by the sizer.
+}
+
+// Bytes returns the total estimated memory used by the Tree.
+func (s Stats) Bytes() int {
+	return s.NodeBytes + s.ItemBytes
+}
+
+// Stats walks the Tree and returns Stats describing it.  If sizer is not nil, it is called with
+// every item to find out how much memory the item refers to outside of the node it is stored in,
+// such as the bytes of a string or the elements of a slice.  The memory estimate counts every node,
+// including those t shares with other Trees, and does not include allocator overhead.
+//
+// Stats visits every node in the Tree, so it takes O(n) time.
+func (t *Tree[T]) Stats(sizer func(T) int) Stats {
+	res := Stats{Balance: map[int]int{}, Generations: map[uint64]int{}}
+	var sum, sumsq float64
+	var walk func(n *node[T], depth int)
+	walk = func(n *node[T], depth int) {
+		if n == nil {
+			return
+		}
+		res.Count++
+		res.Height = max(res.Height, depth+1)
+		sum += float64(depth)
+		sumsq += float64(depth) * float64(depth)
+		res.Balance[int(n.c[r].height())-int(n.c[l].height())]++
+		res.Generations[n.gen()]++
+		if sizer != nil {
+			res.ItemBytes += sizer(n.i)
+		}
+		walk(n.c[l], depth+1)
+		walk(n.c[r], depth+1)
+	}
+	walk(t.root, 0)
+	if res.Count > 0 {
+		res.DepthAvg = sum / float64(res.Count)
+		res.DepthStdDev = math.Sqrt(max(0, sumsq/float64(res.Count)-res.DepthAvg*res.DepthAvg))
+	}
+	res.NodeBytes = res.Count * int(unsafe.Sizeof(node[T]{}))
+	return res
+}
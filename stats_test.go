@@ -2,6 +2,9 @@ package avl
 
 import (
 	"math"
+	"math/rand"
+	"testing"
+	"unsafe"
 )
 
 // getKeyHeight returns an item in the Tree with key @key, and it's height in the Tree
@@ -16,7 +19,7 @@ func (t *Tree[T]) getHeight(h *node[T], item CompareAgainst[T]) (T, int) {
 	}
 	switch item(h.i) {
 	case -1:
-		result, depth := t.getHeight(h.c[l], item)
+		result, depth := t.getHeight(h.c[r], item)
 		return result, depth + 1
 	case 1:
 		result, depth := t.getHeight(h.c[l], item)
@@ -29,52 +32,41 @@ func (t *Tree[T]) getHeight(h *node[T], item CompareAgainst[T]) (T, int) {
 // heightStats returns the average and standard deviation of the height
 // of elements in the Tree
 func (t *Tree[T]) heightStats() (avg, stddev float64) {
-	av := &AvgVar{}
-	heightStats(t.root, 0, av)
-	return av.GetAvg(), av.GetStdDev()
+	s := t.Stats(nil)
+	return s.DepthAvg, s.DepthStdDev
 }
 
-func heightStats[T any](h *node[T], d int, av *AvgVar) {
-	if h == nil {
-		return
+func TestStats(t *testing.T) {
+	tree := New[int](il, rand.New(rand.NewSource(41)).Perm(1000)...)
+	fork := tree.Insert(1000)
+	s := fork.Stats(func(int) int { return 8 })
+	if s.Count != 1001 || s.Height != int(fork.root.h()) {
+		t.Fatalf("Stats: got %d items with height %d", s.Count, s.Height)
 	}
-	av.Add(float64(d))
-	if h.c[l] != nil {
-		heightStats(h.c[l], d+1, av)
+	var sum, sumsq float64
+	for i := 0; i <= 1000; i++ {
+		v, depth := fork.getKeyHeight(fork.Cmp(i))
+		if v != i {
+			t.Fatalf("getKeyHeight(%d) found %d", i, v)
+		}
+		sum += float64(depth)
+		sumsq += float64(depth * depth)
 	}
-	if h.c[r] != nil {
-		heightStats(h.c[r], d+1, av)
+	avg := sum / 1001
+	if math.Abs(s.DepthAvg-avg) > 1e-9 || math.Abs(s.DepthStdDev-math.Sqrt(sumsq/1001-avg*avg)) > 1e-9 {
+		t.Fatalf("Stats: got depth %f ± %f, expected %f", s.DepthAvg, s.DepthStdDev, avg)
+	}
+	if s.Balance[-1]+s.Balance[0]+s.Balance[1] != s.Count {
+		t.Fatalf("Stats: bad balance histogram %v", s.Balance)
+	}
+	// Only the path to the new item (plus any rotated nodes) was copied into the Fork's generation.
+	if len(s.Generations) != 2 || s.Generations[fork.gen] == 0 || s.Generations[fork.gen] > s.Height+2 {
+		t.Fatalf("Stats: bad generation histogram %v", s.Generations)
+	}
+	if s.NodeBytes != 1001*int(unsafe.Sizeof(node[int]{})) || s.ItemBytes != 8*1001 || s.Bytes() != s.NodeBytes+s.ItemBytes {
+		t.Fatalf("Stats: bad memory estimate %d + %d", s.NodeBytes, s.ItemBytes)
+	}
+	if s = New[int](il).Stats(nil); s.Count != 0 || s.Height != 0 || s.DepthAvg != 0 {
+		t.Fatalf("Stats of an empty Tree: got %+v", s)
 	}
 }
-
-// AvgVar maintains the average and variance of a stream of numbers
-// in a space-efficient manner.
-type AvgVar struct {
-	count      int64
-	sum, sumsq float64
-}
-
-func (av *AvgVar) Init() {
-	av.count = 0
-	av.sum = 0.0
-	av.sumsq = 0.0
-}
-
-func (av *AvgVar) Add(sample float64) {
-	av.count++
-	av.sum += sample
-	av.sumsq += sample * sample
-}
-
-func (av *AvgVar) GetCount() int64 { return av.count }
-
-func (av *AvgVar) GetAvg() float64 { return av.sum / float64(av.count) }
-
-func (av *AvgVar) GetTotal() float64 { return av.sum }
-
-func (av *AvgVar) GetVar() float64 {
-	a := av.GetAvg()
-	return av.sumsq/float64(av.count) - a*a
-}
-
-func (av *AvgVar) GetStdDev() float64 { return math.Sqrt(av.GetVar()) }
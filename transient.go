@@ -0,0 +1,103 @@
+package avl
+
+import "iter"
+
+const persisted = `Transient used after Persist`
+
+// Transient is a mutable builder for a Tree.  It owns a fresh generation of the Tree it was created
+// from, so its Insert and Delete methods modify the nodes it has already copied in place instead of
+// copying them again.  That amortizes the cost of copy-on-write across every change made to the
+// Transient, even when reads, inserts and deletes are interleaved across many function calls.
+//
+// A Transient is not safe for concurrent use, and any Iter or iter.Seq obtained from it is only valid
+// until the next Insert or Delete.  Call Persist to get an immutable Tree with the Transient's contents
+// when you are done.  Using the Transient after that will panic.
+type Transient[T any] struct {
+	t   *Tree[T]
+	ins *nodeStack[T]
+}
+
+// Transient returns a new Transient that starts out with the same contents as t.
+// t is left unchanged, no matter what is done to the Transient.
+func (t *Tree[T]) Transient() *Transient[T] {
+	res := t.Fork()
+	return &Transient[T]{t: res, ins: res.getNs()}
+}
+
+// tree returns the Tree the Transient is modifying, or panics if it has been persisted.
+func (tr *Transient[T]) tree() *Tree[T] {
+	if tr.t == nil {
+		panic(persisted)
+	}
+	return tr.t
+}
+
+// Persist freezes the Transient into an immutable Tree and returns it.
+// The Transient must not be used afterwards.
+func (tr *Transient[T]) Persist() *Tree[T] {
+	res := tr.tree()
+	res.putNs(tr.ins)
+	tr.t, tr.ins = nil, nil
+	return res
+}
+
+// Insert adds items to the Transient, replacing any equal items already in it.
+func (tr *Transient[T]) Insert(items ...T) {
+	t := tr.tree()
+	for i := range items {
+		t.insertOne(tr.ins, items[i])
+	}
+}
+
+// Delete removes item from the Transient, returning the removed item and whether it was found.
+func (tr *Transient[T]) Delete(item T) (deleted T, found bool) {
+	t := tr.tree()
+	deleted, found = t.deleteOne(tr.ins, item)
+	// deleteOne resets the generation of a Tree it empties, but the Transient
+	// still owns its generation and must keep using it for any later Inserts.
+	t.gen = tr.ins.gen
+	return
+}
+
+// Len returns the number of items in the Transient.
+func (tr *Transient[T]) Len() int { return tr.tree().Len() }
+
+// Get returns the item in the Transient that is equal to CompareAgainst and true,
+// or a zero T and false if there is no such value.
+func (tr *Transient[T]) Get(cmp CompareAgainst[T]) (item T, found bool) {
+	return tr.tree().Get(cmp)
+}
+
+// Has returns true if the Transient contains an element equal to CompareAgainst.
+func (tr *Transient[T]) Has(cmp CompareAgainst[T]) bool {
+	return tr.tree().Has(cmp)
+}
+
+// Fetch returns the exact match for item, true if it is in the Transient,
+// or the zero value for T, false if it is not.
+func (tr *Transient[T]) Fetch(item T) (T, bool) {
+	return tr.tree().Fetch(item)
+}
+
+// Cmp takes a reference T and makes a valid CompareAgainst using the Transient's LessThan.
+func (tr *Transient[T]) Cmp(reference T) CompareAgainst[T] {
+	return tr.tree().Cmp(reference)
+}
+
+// Iterator returns an Iter over the current contents of the Transient that works like Tree.Iterator.
+// It must not be used after the next Insert or Delete.
+func (tr *Transient[T]) Iterator(start, stop Test[T]) Iter[T] {
+	return tr.tree().Iterator(start, stop)
+}
+
+// All returns an Iter over the current contents of the Transient that works like Tree.All.
+// It must not be used after the next Insert or Delete.
+func (tr *Transient[T]) All() Iter[T] {
+	return tr.tree().All()
+}
+
+// Values returns an iter.Seq over the current contents of the Transient in ascending order.
+// The Transient must not be modified while the iter.Seq is in use.
+func (tr *Transient[T]) Values() iter.Seq[T] {
+	return tr.tree().Values()
+}
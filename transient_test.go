@@ -0,0 +1,82 @@
+package avl
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestTransient(t *testing.T) {
+	src := rand.New(rand.NewSource(31))
+	base := New[int](il, seq(0, 100)...)
+	tr := base.Transient()
+	model := map[int]bool{}
+	for i := 0; i < 100; i++ {
+		model[i] = true
+	}
+	for i := 0; i < 2000; i++ {
+		v := src.Intn(300)
+		if src.Intn(2) == 0 {
+			tr.Insert(v)
+			model[v] = true
+		} else {
+			_, found := tr.Delete(v)
+			if found != model[v] {
+				t.Fatalf("Delete(%d): found %v, expected %v", v, found, model[v])
+			}
+			delete(model, v)
+		}
+		if _, found := tr.Fetch(v); found != model[v] {
+			t.Fatalf("Fetch(%d) after change: found %v, expected %v", v, found, model[v])
+		}
+	}
+	if tr.Len() != len(model) {
+		t.Fatalf("Len: expected %d, got %d", len(model), tr.Len())
+	}
+	var expect []int
+	for i := 0; i < 300; i++ {
+		if model[i] {
+			expect = append(expect, i)
+		}
+	}
+	var got []int
+	for v := range tr.Values() {
+		got = append(got, v)
+	}
+	if !reflect.DeepEqual(expect, got) {
+		t.Fatalf("Values: expected %v, got %v", expect, got)
+	}
+	res := tr.Persist()
+	res.root.balanced(t)
+	if !reflect.DeepEqual(expect, treeItems(res)) {
+		t.Fatalf("Persist did not preserve the contents")
+	}
+	if !reflect.DeepEqual(seq(0, 100), treeItems(base)) {
+		t.Fatalf("Transient modified the Tree it was made from")
+	}
+	// Changing the persisted Tree must not affect the original.
+	res2 := res.Insert(1000)
+	if res.Len() != len(expect) || res2.Len() != len(expect)+1 {
+		t.Fatalf("Persisted Tree was modified in place")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Using a Transient after Persist did not panic")
+		}
+	}()
+	tr.Insert(5)
+}
+
+func TestTransientEmptied(t *testing.T) {
+	tr := New[int](il, 1).Transient()
+	tr.Delete(1)
+	tr.Insert(5, 6, 7)
+	res := tr.Persist()
+	if err := res.Check(); err != nil {
+		t.Fatalf("Check of a Transient emptied and refilled: %v", err)
+	}
+	res2 := res.Insert(10)
+	if !reflect.DeepEqual([]int{5, 6, 7}, treeItems(res)) || !reflect.DeepEqual([]int{5, 6, 7, 10}, treeItems(res2)) {
+		t.Fatalf("Persisted Tree was modified in place")
+	}
+}
@@ -0,0 +1,177 @@
+package avl
+
+import "sync/atomic"
+
+// Event holds the Changes made to the part of a Tree a Watcher is watching between two versions of a Ref.
+type Event[T any] struct {
+	From, To uint64 // The Ref versions the Changes were computed between.
+	Changes  []Change[T]
+}
+
+// Watcher receives Events for the items in a Ref that fall between a start and stop Test.
+// Watchers are created by Ref.Watch and Ref.WatchFunc.
+type Watcher[T any] struct {
+	// C receives an Event whenever a Tree published to the Ref changes an item in the Watcher's window.
+	// It is closed by Stop.  Watchers created by WatchFunc do not expose it.
+	C           <-chan Event[T]
+	c           chan Event[T]
+	r           *Ref[T]
+	start, stop Test[T]
+	eq          func(a, b T) bool
+	base        *Tree[T] // The Tree as of the last Event delivered.
+	version     uint64   // The version of base.
+	pending     bool     // deliver is waiting for room in c.  Protected by r.wmu.
+	done        chan struct{}
+	coalesced   atomic.Uint64
+}
+
+// inWindow returns true if item is in the part of the Tree w is watching.
+func (w *Watcher[T]) inWindow(item T) bool {
+	return (w.start == nil || !w.start(item)) && (w.stop == nil || !w.stop(item))
+}
+
+// changes returns the Changes in w's window between base and t.
+func (w *Watcher[T]) changes(t *Tree[T]) (res []Change[T]) {
+	diff(w.base, t, w.eq, func(c Change[T]) bool {
+		item := c.New
+		if c.Kind == Removed {
+			item = c.Old
+		}
+		if w.stop != nil && w.stop(item) {
+			return false
+		}
+		if w.inWindow(item) {
+			res = append(res, c)
+		}
+		return true
+	})
+	return
+}
+
+// Coalesced returns the number of times a new Tree was published while w's buffer was full.
+// The Changes in those Trees are not lost.  Instead, they are delivered as soon as there is room.
+func (w *Watcher[T]) Coalesced() uint64 {
+	return w.coalesced.Load()
+}
+
+// Stop unregisters w from its Ref and closes its channel.  It is safe to call Stop more than once.
+func (w *Watcher[T]) Stop() {
+	w.r.wmu.Lock()
+	defer w.r.wmu.Unlock()
+	if _, ok := w.r.watchers[w]; !ok {
+		return
+	}
+	delete(w.r.watchers, w)
+	w.r.nwatch.Add(-1)
+	close(w.done)
+	if !w.pending {
+		// Otherwise deliver closes c once it stops waiting to send on it.
+		close(w.c)
+	}
+	w.base = nil
+}
+
+// Watch registers a Watcher that is sent an Event whenever a newly published Tree adds, removes, or
+// changes any items that start and stop do not exclude.  start and stop work the same way they do for
+// Tree.Iterator.  Items that are in both versions are passed to eq to see if they changed, the same
+// way Diff does it.
+//
+// The Watcher's channel holds up to buffer Events.  Events are computed and sent by whichever goroutine
+// published the new Tree, unless the channel is full.  Then a goroutine of the Watcher's own waits for
+// room to send that Event, after which it sends one more Event covering every change published while it
+// was waiting, without waiting for another publish.  A Watcher that is never drained keeps that goroutine
+// and the Trees it is diffing alive.  Call Stop when the Watcher is no longer needed.
+func (r *Ref[T]) Watch(start, stop Test[T], eq func(a, b T) bool, buffer int) *Watcher[T] {
+	if buffer < 1 {
+		buffer = 1
+	}
+	w := &Watcher[T]{c: make(chan Event[T], buffer), done: make(chan struct{}), r: r, start: start, stop: stop, eq: eq}
+	w.C = w.c
+	r.wmu.Lock()
+	defer r.wmu.Unlock()
+	if r.watchers == nil {
+		r.watchers = map[*Watcher[T]]struct{}{}
+	}
+	w.base, w.version = r.LoadVersion()
+	r.watchers[w] = struct{}{}
+	r.nwatch.Add(1)
+	return w
+}
+
+// WatchFunc is Watch, except that it calls fn with each Event from a goroutine of its own instead of
+// exposing the channel.  fn is called with one Event at a time, and may still be running when Stop returns.
+func (r *Ref[T]) WatchFunc(start, stop Test[T], eq func(a, b T) bool, buffer int, fn func(Event[T])) *Watcher[T] {
+	w := r.Watch(start, stop, eq, buffer)
+	w.C = nil
+	go func(c <-chan Event[T]) {
+		for ev := range c {
+			fn(ev)
+		}
+	}(w.c)
+	return w
+}
+
+// notify sends Events to any Watchers whose windows changed since they were last sent an Event.
+// It always works against the latest Tree, so when several goroutines publish at once the
+// Watchers see their changes in as few Events as possible, but always in order.
+func (r *Ref[T]) notify() {
+	if r.nwatch.Load() == 0 {
+		return
+	}
+	r.wmu.Lock()
+	defer r.wmu.Unlock()
+	t, version := r.LoadVersion()
+	for w := range r.watchers {
+		if w.version >= version {
+			continue
+		}
+		if w.pending {
+			// deliver will pick this version up once it has room.
+			w.coalesced.Add(1)
+			continue
+		}
+		if changes := w.changes(t); len(changes) > 0 {
+			ev := Event[T]{From: w.version, To: version, Changes: changes}
+			select {
+			case w.c <- ev:
+			default:
+				w.coalesced.Add(1)
+				w.pending = true
+				go r.deliver(w, ev, t)
+				continue
+			}
+		}
+		w.base, w.version = t, version
+	}
+}
+
+// deliver waits for room in w's channel to send ev, which holds the Changes up to t.  It then keeps
+// sending Events until w has seen every Tree published to r, since notify skips w while it is pending.
+func (r *Ref[T]) deliver(w *Watcher[T], ev Event[T], t *Tree[T]) {
+	for {
+		select {
+		case w.c <- ev:
+		case <-w.done:
+		}
+		r.wmu.Lock()
+		select {
+		case <-w.done:
+			close(w.c)
+			r.wmu.Unlock()
+			return
+		default:
+		}
+		w.base, w.version = t, ev.To
+		var version uint64
+		t, version = r.LoadVersion()
+		changes := w.changes(t)
+		if len(changes) == 0 {
+			w.base, w.version = t, version
+			w.pending = false
+			r.wmu.Unlock()
+			return
+		}
+		ev = Event[T]{From: w.version, To: version, Changes: changes}
+		r.wmu.Unlock()
+	}
+}
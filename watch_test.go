@@ -0,0 +1,117 @@
+package avl
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	ref := NewRef(New[ovr](ol))
+	cmp := func(i int) CompareAgainst[ovr] { return ref.Load().Cmp(ovr{i: i}) }
+	w := ref.Watch(Lt(cmp(10)), Gt(cmp(20)), ovrEq, 4)
+	defer w.Stop()
+	insert := func(items ...ovr) {
+		ref.Update(func(t *Tree[ovr]) *Tree[ovr] { return t.Insert(items...) })
+	}
+	insert(ovr{i: 5}, ovr{i: 30})
+	select {
+	case ev := <-w.C:
+		t.Fatalf("Watcher got an Event for changes outside its window: %v", ev)
+	default:
+	}
+	insert(ovr{i: 10}, ovr{i: 15}, ovr{i: 25})
+	ev := <-w.C
+	expect := []Change[ovr]{{Kind: Added, New: ovr{i: 10}}, {Kind: Added, New: ovr{i: 15}}}
+	if ev.From != 1 || ev.To != 2 || !reflect.DeepEqual(expect, ev.Changes) {
+		t.Fatalf("Event: expected %v from 1 to 2, got %+v", expect, ev)
+	}
+	ref.Update(func(t *Tree[ovr]) *Tree[ovr] {
+		t, _, _ = t.Delete(ovr{i: 10})
+		return t.Insert(ovr{i: 15, mark: 1}, ovr{i: 20})
+	})
+	ev = <-w.C
+	expect = []Change[ovr]{{Kind: Removed, Old: ovr{i: 10}}, {Kind: Changed, Old: ovr{i: 15}, New: ovr{i: 15, mark: 1}}, {Kind: Added, New: ovr{i: 20}}}
+	if !reflect.DeepEqual(expect, ev.Changes) {
+		t.Fatalf("Event: expected %v, got %v", expect, ev.Changes)
+	}
+	// Fill the buffer, and make sure the Changes that did not fit are delivered once there is room,
+	// without anything else being published.
+	for i := 11; i < 17; i++ {
+		insert(ovr{i: i, mark: 2})
+	}
+	if w.Coalesced() != 2 {
+		t.Fatalf("Coalesced: expected 2, got %d", w.Coalesced())
+	}
+	var got []int
+	for i := 0; i < 4; i++ {
+		for _, c := range (<-w.C).Changes {
+			got = append(got, c.New.i)
+		}
+	}
+	for _, v := range []uint64{8, 9} {
+		select {
+		case ev = <-w.C:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Coalesced Changes up to version %d were never delivered", v)
+		}
+		if ev.From != v-1 || ev.To != v || len(ev.Changes) != 1 || ev.Changes[0].New.i != int(v)+7 {
+			t.Fatalf("Coalesced Event: got %+v", ev)
+		}
+	}
+	w.Stop()
+	w.Stop()
+	if _, ok := <-w.C; ok {
+		t.Fatalf("Stop did not close the channel")
+	}
+	if !reflect.DeepEqual([]int{11, 12, 13, 14}, got) {
+		t.Fatalf("Buffered Events: got %v", got)
+	}
+	// Stopping a Watcher that is waiting for room in its buffer still closes the channel.
+	w = ref.Watch(nil, nil, nil, 1)
+	insert(ovr{i: 50})
+	insert(ovr{i: 51})
+	w.Stop()
+	for range w.C {
+	}
+}
+
+func TestWatchFunc(t *testing.T) {
+	ref := NewRef(New[int](il))
+	var mu sync.Mutex
+	seen := map[int]bool{}
+	done := make(chan struct{})
+	w := ref.WatchFunc(nil, nil, nil, 1, func(ev Event[int]) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, c := range ev.Changes {
+			if !seen[c.New] {
+				seen[c.New] = true
+				if len(seen) == 400 {
+					close(done)
+				}
+			}
+		}
+	})
+	defer w.Stop()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				ref.Update(func(t *Tree[int]) *Tree[int] { return t.Insert(i*100 + j) })
+			}
+		}(i)
+	}
+	wg.Wait()
+	// Anything that was coalesced at the end is delivered without anything else being published.
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		mu.Lock()
+		defer mu.Unlock()
+		t.Fatalf("WatchFunc: saw %d of 400 items", len(seen))
+	}
+}